@@ -0,0 +1,17 @@
+-- PostgreSQL DDL for the unccord-bot-go application
+
+-- Create the favourites table
+CREATE TABLE favourites (
+    id SERIAL PRIMARY KEY,               -- Auto-incrementing ID for each record
+    user_id TEXT NOT NULL,               -- ID of the user who saved the track (Discord user ID)
+    identifier TEXT NOT NULL,            -- Lavalink identifier of the track
+    title TEXT NOT NULL,                 -- Title of the track
+    author TEXT NOT NULL,                -- Author of the track
+    uri TEXT,                            -- Link to the track, if the source provides one
+    encoded TEXT NOT NULL,               -- Encoded Lavalink track, used to play it again
+    saved_at TIMESTAMP DEFAULT NOW(),    -- Timestamp when the track was saved
+    UNIQUE (user_id, identifier)
+);
+
+-- Index for quick lookup by user_id
+CREATE INDEX idx_favourites_user_id ON favourites (user_id);
@@ -0,0 +1,8 @@
+-- PostgreSQL DDL for the unccord-bot-go application
+
+-- Create the guild_prefixes table
+CREATE TABLE guild_prefixes (
+    guild_id TEXT PRIMARY KEY,           -- ID of the guild (Discord guild ID)
+    prefix TEXT NOT NULL,                -- Prefix of the guild's text commands, like "!"
+    updated_at TIMESTAMP DEFAULT NOW()   -- Timestamp of the last prefix change
+);
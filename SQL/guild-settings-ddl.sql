@@ -0,0 +1,9 @@
+-- PostgreSQL DDL for the unccord-bot-go application
+
+-- Create the guild_settings table
+CREATE TABLE guild_settings (
+    guild_id TEXT PRIMARY KEY,           -- ID of the guild (Discord guild ID)
+    always_on_channel_id TEXT,           -- Voice channel the bot stays in while 24/7 mode is on
+    always_on_playlist TEXT,             -- Playlist used to refill the queue in 24/7 mode
+    updated_at TIMESTAMP DEFAULT NOW()   -- Timestamp of the last settings change
+);
@@ -0,0 +1,8 @@
+-- PostgreSQL DDL for the unccord-bot-go application
+
+-- Create the lavalink_sessions table
+CREATE TABLE lavalink_sessions (
+    node_name TEXT PRIMARY KEY,          -- Name of the Lavalink node
+    session_id TEXT NOT NULL,            -- Lavalink session ID to resume on the next start
+    updated_at TIMESTAMP DEFAULT NOW()   -- Timestamp when the session ID was stored
+);
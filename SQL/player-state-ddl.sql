@@ -0,0 +1,16 @@
+-- PostgreSQL DDL for the unccord-bot-go application
+
+-- Create the player_state table
+CREATE TABLE player_state (
+    guild_id TEXT PRIMARY KEY,           -- ID of the guild (Discord guild ID)
+    voice_channel_id TEXT NOT NULL,      -- Voice channel the player was connected to
+    current_track JSONB,                 -- Currently playing track, including its encoded form
+    position BIGINT NOT NULL DEFAULT 0,  -- Playback position of the current track in milliseconds
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    volume INT NOT NULL DEFAULT 100,
+    filters JSONB,                       -- Lavalink filters applied to the player
+    loop_mode INT NOT NULL DEFAULT 0,    -- 0 = off, 1 = track, 2 = queue
+    autoplay BOOLEAN NOT NULL DEFAULT FALSE,
+    queue JSONB NOT NULL DEFAULT '[]',   -- Upcoming tracks, including their encoded form
+    saved_at TIMESTAMP DEFAULT NOW()     -- Timestamp when the state was saved
+);
@@ -2,6 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,6 +14,7 @@ import (
 
 	"unccord-bot-go/config"
 	"unccord-bot-go/handlers"
+	"unccord-bot-go/storage"
 
 	"github.com/disgoorg/disgo"
 	"github.com/disgoorg/disgo/bot"
@@ -18,87 +24,226 @@ import (
 	"log/slog"
 )
 
+// playerStateSaveInterval is how often player states are persisted while the bot runs.
+const playerStateSaveInterval = 30 * time.Second
+
+// nodeMonitorInterval is how often the Lavalink nodes are checked for failures.
+const nodeMonitorInterval = 5 * time.Second
+
+// Startup steps are each bounded by their own timeout so a hanging dependency cannot stall the bot forever.
+const (
+	dbConnectTimeout       = 10 * time.Second
+	gatewayOpenTimeout     = 30 * time.Second
+	commandRegisterTimeout = 30 * time.Second
+)
+
+// configWatchInterval is how often the config file is checked for changes.
+const configWatchInterval = 10 * time.Second
+
+// shutdownTimeout bounds the whole shutdown sequence.
+const shutdownTimeout = 15 * time.Second
+
+// debugReadHeaderTimeout bounds how long the debug endpoint waits for a request's headers.
+const debugReadHeaderTimeout = 5 * time.Second
+
 func main() {
 	slog.Info("Starting unccord-bot-go...")
 
+	// The root context is cancelled on SIGINT or SIGTERM and stops all background tasks
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to the YAML config file; environment variables override its settings")
+	profile := flag.String("profile", "", "configuration profile to use (dev or prod)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [sync-commands]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Without a command the bot runs. sync-commands registers the slash commands and exits.")
+		fmt.Fprintln(flag.CommandLine.Output(), "\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	// Load configuration
-	config.LoadConfig()
-	if config.AppConfig.DiscordToken == "" {
-		slog.Error("No Discord token provided. Check your configuration.")
+	if err := config.LoadConfig(*configPath, *profile); err != nil {
+		slog.Error("Failed to load configuration", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	switch command := flag.Arg(0); command {
+	case "":
+	case "sync-commands":
+		if err := syncCommands(ctx); err != nil {
+			slog.Error("Failed to sync commands", slog.Any("err", err))
+			os.Exit(1)
+		}
 		return
+	default:
+		slog.Error("Unknown command", "command", command)
+		os.Exit(2)
 	}
 
-	// Setup graceful shutdown signal handling
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	setupSignalHandler()
+	// A failed startup exits with an error so restart policies that only act on failure bring the bot back.
+	// run has shut down what it started by the time it returns.
+	if err := run(ctx); err != nil {
+		slog.Error("unccord-bot-go stopped", slog.Any("err", err))
+		os.Exit(1)
+	}
+}
+
+// run starts the bot and keeps it running until ctx is done. It returns an error if the bot could not be
+// started.
+func run(ctx context.Context) error {
+	// Keep data in the database if one is configured, otherwise in memory until the bot stops
+	var store storage.Store = storage.NewMemory()
+	if config.Get().DatabaseEnabled() {
+		dbCtx, cancel := context.WithTimeout(ctx, dbConnectTimeout)
+		config.ConnectDB(dbCtx)
+		cancel()
+		store = storage.NewPostgres(config.DB)
+	}
+
+	// Initialize bot handlers with the enabled modules
+	b := handlers.NewHandler(ctx, store)
+	for _, module := range b.Modules() {
+		slog.Info("Module enabled", "module", module.Name)
+	}
 
-	// Initialize bot handlers
-	b := handlers.NewHandler()
+	if addr := config.Get().Debug.Addr; addr != "" {
+		go serveDebug(ctx, addr)
+	}
 
 	// Create the bot client
-	client, err := disgo.New(config.AppConfig.DiscordToken,
+	client, err := disgo.New(config.Get().Discord.Token.Value(),
 		bot.WithGatewayConfigOpts(
-			gateway.WithIntents(gateway.IntentGuilds, gateway.IntentGuildVoiceStates, gateway.IntentGuildMessages, gateway.IntentMessageContent),
+			gateway.WithIntents(b.Intents()),
 		),
 		bot.WithCacheConfigOpts(
-			cache.WithCaches(cache.FlagVoiceStates),
+			cache.WithCaches(b.Caches()),
 		),
 		bot.WithEventListeners(b),
 	)
 	if err != nil {
-		slog.Error("Error while building client", slog.Any("err", err))
-		return
+		if config.DB != nil {
+			config.DB.Close()
+		}
+		return fmt.Errorf("error building client: %w", err)
 	}
 	b.Client = client
+	defer shutdown(b)
 
 	// Initialize Lavalink with the loaded config
-	b.Lavalink = disgolink.New(client.ApplicationID())
-	if err = setupLavalink(ctx, b); err != nil {
-		slog.Error("Failed to setup Lavalink", slog.Any("err", err))
-		return
+	if config.Get().Modules.Music {
+		b.Lavalink = disgolink.New(client.ApplicationID(),
+			disgolink.WithListenerFunc(b.OnTrackEnd),
+		)
+		setupLavalink(ctx, b)
 	}
 
 	// Connect to Discord Gateway
-	if err = client.OpenGateway(ctx); err != nil {
-		slog.Error("Error connecting to Discord gateway", slog.Any("err", err))
-		return
+	gatewayCtx, cancel := context.WithTimeout(ctx, gatewayOpenTimeout)
+	err = client.OpenGateway(gatewayCtx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("error connecting to Discord gateway: %w", err)
 	}
-	defer client.Close(context.TODO())
 
 	// Register commands after connecting to the gateway
-	if err = b.RegisterCommands(client); err != nil {
-		slog.Error("Failed to register commands", slog.Any("err", err))
-		return
+	commandsCtx, cancel := context.WithTimeout(ctx, commandRegisterTimeout)
+	err = b.SyncCommands(commandsCtx, client)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to register commands: %w", err)
+	}
+
+	if config.Get().Modules.Music {
+		// Periodically persist player states so a crash loses as little as possible
+		go b.PersistPlayerStates(ctx, playerStateSaveInterval)
+		go b.MonitorNodes(ctx, nodeMonitorInterval)
 	}
 
+	// Apply config file changes and SIGHUP reloads while running
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go config.Watch(ctx, configWatchInterval, reload)
+
 	slog.Info("unccord-bot-go is now running. Press CTRL-C to exit.")
-	<-setupSignalHandler()
+	<-ctx.Done()
+	slog.Info("Shutting down...")
+	return nil
 }
 
-// setupLavalink initializes Lavalink nodes based on the configuration.
-func setupLavalink(ctx context.Context, b *handlers.Handler) error {
-	node, err := b.Lavalink.AddNode(ctx, disgolink.NodeConfig{
-		Name:     "default",
-		Address:  config.AppConfig.LavalinkHost + ":" + config.AppConfig.LavalinkPort,
-		Password: config.AppConfig.LavalinkPassword,
-	})
+// syncCommands registers the commands of the enabled modules with Discord without starting the bot.
+// It only needs the REST API, so neither the gateway nor the database is connected.
+func syncCommands(ctx context.Context) error {
+	b := handlers.NewHandler(ctx, storage.NewMemory())
+	client, err := disgo.New(config.Get().Discord.Token.Value())
 	if err != nil {
 		return err
 	}
+	defer client.Close(context.Background())
 
-	version, err := node.Version(ctx)
-	if err != nil {
-		return err
+	ctx, cancel := context.WithTimeout(ctx, commandRegisterTimeout)
+	defer cancel()
+	return b.SyncCommands(ctx, client)
+}
+
+// serveDebug serves the expvar counters, like the panics recovered by the handlers, at /debug/vars on addr
+// until ctx is done.
+func serveDebug(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: debugReadHeaderTimeout}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	slog.Info("Serving debug variables", "addr", addr, "path", "/debug/vars")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Debug endpoint stopped", slog.Any("err", err))
+	}
+}
+
+// setupLavalink starts connecting the configured Lavalink nodes in the background and keeps the
+// nodes in sync with config reloads. The bot keeps running without music until at least one node is connected.
+func setupLavalink(ctx context.Context, b *handlers.Handler) {
+	for _, node := range config.Get().Lavalink.Nodes {
+		b.StartNode(ctx, node)
 	}
 
-	slog.Info("Lavalink node connected", slog.String("version", version), slog.String("session_id", node.SessionID()))
-	return nil
+	config.OnReload(func(old, new *config.Config) {
+		b.UpdateNodes(ctx, old.Lavalink.Nodes, new.Lavalink.Nodes)
+	})
 }
 
-// setupSignalHandler handles graceful shutdown signals.
-func setupSignalHandler() chan os.Signal {
-	s := make(chan os.Signal, 1)
-	signal.Notify(s, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
-	return s
+// shutdown stops the bot in order within shutdownTimeout: it saves the queues, destroys the
+// players unless the Lavalink session is kept for resuming, then closes Lavalink, the gateway and the
+// database. Steps of disabled modules are skipped.
+func shutdown(b *handlers.Handler) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if b.Lavalink != nil {
+		// Save queues and playback positions so they can be restored on the next start
+		if err := b.SavePlayerStates(ctx); err != nil {
+			slog.Error("Failed to save player states", slog.Any("err", err))
+		}
+
+		// A resumable session keeps its players on the node until the bot reconnects
+		if config.Get().Lavalink.ResumeTimeout <= 0 {
+			b.DestroyPlayers(ctx)
+		}
+
+		b.Lavalink.Close()
+	}
+
+	b.Client.Close(ctx)
+
+	if config.DB != nil {
+		if err := config.DB.Close(); err != nil {
+			slog.Error("Failed to close database", slog.Any("err", err))
+		}
+	}
+	slog.Info("Shutdown complete")
 }
@@ -4,114 +4,344 @@ package config
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/disgoorg/snowflake/v2"
 )
 
+// Profiles the bot can run with. The profile selects the matching section of the config file's
+// profiles block and is available to the rest of the bot through Config.IsDev.
+const (
+	ProfileDev  = "dev"
+	ProfileProd = "prod"
+)
+
 // Config holds the configuration details for the bot, including database credentials, starboard settings, Discord token, and Lavalink configuration.
 type Config struct {
-	DBHost            string
-	DBPort            string
-	DBUser            string
-	DBPassword        string
-	DBName            string
-	StarboardChannelID snowflake.ID
-	StarThreshold     int
-	LavalinkHost      string
-	LavalinkPort      string
-	LavalinkPassword  string
-	DiscordToken      string
-}
-
-// AppConfig holds the global configuration for the bot.
-var AppConfig Config
-
-// LoadConfig initializes and validates the bot's configuration.
-func LoadConfig() {
-	log.Println("Starting to load configuration...")
+	Profile    string          `yaml:"profile"`
+	Modules    ModulesConfig   `yaml:"modules"`
+	Discord    DiscordConfig   `yaml:"discord"`
+	Database   DatabaseConfig  `yaml:"database"`
+	Lavalink   LavalinkConfig  `yaml:"lavalink"`
+	Starboard  StarboardConfig `yaml:"starboard"`
+	Music      MusicConfig     `yaml:"music"`
+	RateLimits RateLimitConfig `yaml:"rate_limits"`
+	Debug      DebugConfig     `yaml:"debug"`
+}
 
-	AppConfig = Config{
-		DBHost:             mustGetEnv("DB_HOST"),
-		DBPort:             mustGetEnv("DB_PORT"),
-		DBUser:             mustGetEnv("DB_USER"),
-		DBPassword:         mustGetEnv("DB_PASSWORD"),
-		DBName:             mustGetEnv("DB_NAME"),
-		StarboardChannelID: mustParseSnowflake("STARBOARD_CHANNEL_ID"),
-		StarThreshold:      mustParseInt("STAR_THRESHOLD"),
-		DiscordToken:       loadAndValidateDiscordToken(),
-		LavalinkHost:       mustGetEnv("SERVER_ADDRESS"),
-		LavalinkPort:       mustGetEnv("SERVER_PORT"),
-		LavalinkPassword:   mustGetEnv("LAVALINK_SERVER_PASSWORD"),
-	}
+// ModulesConfig turns the bot's features on or off. The config section of a module is only
+// required while the module is enabled.
+type ModulesConfig struct {
+	Music     bool `yaml:"music"`
+	Starboard bool `yaml:"starboard"`
+}
 
-	if err := ValidateConfig(); err != nil {
-		log.Fatalf("Configuration validation failed: %v", err)
-	}
+// DiscordConfig holds the Discord bot credentials and where its commands are registered.
+type DiscordConfig struct {
+	Token Secret `yaml:"token"`
+	// DevGuildID is the guild that receives the commands with the dev profile. Guild commands update
+	// instantly, unlike global ones.
+	DevGuildID snowflake.ID `yaml:"dev_guild_id"`
+}
 
-	log.Println("Configuration loaded successfully")
+// DatabaseConfig holds the PostgreSQL connection settings. The database is optional for the music
+// module; without it, favourites, 24/7 settings and saved players are only kept in memory.
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password Secret `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
 }
 
-// mustGetEnv retrieves environment variables or logs a fatal error if not set.
-func mustGetEnv(key string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		log.Fatalf("Environment variable %s is missing or empty", key)
+// LavalinkConfig holds the Lavalink nodes and session settings.
+type LavalinkConfig struct {
+	Nodes []LavalinkNode `yaml:"nodes"`
+	// ResumeTimeout is how long Lavalink keeps players alive while the bot restarts. 0 disables resuming.
+	ResumeTimeout time.Duration `yaml:"resume_timeout"`
+}
+
+// LavalinkNode describes a Lavalink server the bot can play music on. Region is matched
+// against the RTC region of a voice channel (e.g. "us" matches "us-east") when picking a node.
+type LavalinkNode struct {
+	Name     string `json:"name" yaml:"name"`
+	Address  string `json:"address" yaml:"address"`
+	Password Secret `json:"password" yaml:"password"`
+	Region   string `json:"region" yaml:"region"`
+	Secure   bool   `json:"secure" yaml:"secure"`
+}
+
+// StarboardConfig holds the starboard settings.
+type StarboardConfig struct {
+	ChannelID snowflake.ID `yaml:"channel_id"`
+	Threshold int          `yaml:"threshold"`
+	Color     int          `yaml:"color"`
+}
+
+// MusicConfig holds the music player defaults. A timeout of 0 disables the corresponding timer.
+type MusicConfig struct {
+	IdleTimeout         time.Duration `yaml:"idle_timeout"`
+	EmptyChannelTimeout time.Duration `yaml:"empty_channel_timeout"`
+	PauseOnEmpty        bool          `yaml:"pause_on_empty"`
+	// Channels are the text channels in which links and audio files that members post while in voice are
+	// played. Links are ignored in all channels if there are none.
+	Channels []snowflake.ID `yaml:"channels"`
+}
+
+// RateLimitConfig limits how often commands can be used. Every command has a bucket per user and one per
+// guild; a use is allowed while both have a token left. Message links count as the "play" command and the
+// player buttons as "panel".
+type RateLimitConfig struct {
+	// BypassAdmins exempts members with the Administrator permission.
+	BypassAdmins bool                 `yaml:"bypass_admins"`
+	Default      RateLimit            `yaml:"default"`
+	Commands     map[string]RateLimit `yaml:"commands"` // Replace the default for the named commands
+}
+
+// RateLimit holds the user and guild buckets of a command.
+type RateLimit struct {
+	User  Bucket `yaml:"user"`
+	Guild Bucket `yaml:"guild"`
+}
+
+func (l RateLimit) negative() bool {
+	return l.User.Burst < 0 || l.User.Interval < 0 || l.Guild.Burst < 0 || l.Guild.Interval < 0
+}
+
+// Bucket is a token bucket: it holds up to Burst tokens and regains one every Interval. A Burst of 0
+// means unlimited.
+type Bucket struct {
+	Burst    int           `yaml:"burst"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// DebugConfig holds the optional debug endpoint.
+type DebugConfig struct {
+	// Addr is where /debug/vars serves the bot's expvar counters, such as the recovered panics. The endpoint
+	// is off if Addr is empty. It is not authenticated, so it should only listen on localhost or a private network.
+	Addr string `yaml:"addr"`
+}
+
+// RateLimit returns the rate limit of a command.
+func (c RateLimitConfig) RateLimit(command string) RateLimit {
+	if limit, ok := c.Commands[command]; ok {
+		return limit
 	}
-	return value
+	return c.Default
 }
 
-// mustParseSnowflake parses a snowflake ID from an environment variable.
-func mustParseSnowflake(key string) snowflake.ID {
-	idStr := mustGetEnv(key)
-	id, err := snowflake.Parse(idStr)
-	if err != nil {
-		log.Fatalf("Invalid %s: %v", key, err)
+// DatabaseEnabled reports whether a database is configured.
+func (c Config) DatabaseEnabled() bool {
+	return c.Database.Host != ""
+}
+
+// IsDev reports whether the bot runs with the development profile.
+func (c Config) IsDev() bool {
+	return c.Profile == ProfileDev
+}
+
+// current holds the active configuration. It is replaced as a whole when the config is reloaded.
+var current atomic.Pointer[Config]
+
+// Get returns the active configuration. The returned value must not be modified; callers that use
+// several settings together should call Get once so they see a consistent snapshot across reloads.
+func Get() *Config {
+	return current.Load()
+}
+
+// source remembers where the configuration was loaded from so it can be reloaded.
+var source struct {
+	path    string
+	profile string
+}
+
+// defaultConfig returns the settings used for everything the config file and environment leave unset.
+func defaultConfig() Config {
+	return Config{
+		Profile: ProfileProd,
+		Modules: ModulesConfig{
+			Music:     true,
+			Starboard: true,
+		},
+		Database: DatabaseConfig{
+			Port:    "5432",
+			SSLMode: "disable",
+		},
+		Lavalink: LavalinkConfig{
+			ResumeTimeout: time.Minute,
+		},
+		Starboard: StarboardConfig{
+			Color: 0xFFAC33, // Star yellow
+		},
+		Music: MusicConfig{
+			IdleTimeout:         5 * time.Minute,
+			EmptyChannelTimeout: 2 * time.Minute,
+			PauseOnEmpty:        true,
+		},
+		RateLimits: RateLimitConfig{
+			BypassAdmins: true,
+			Default: RateLimit{
+				User:  Bucket{Burst: 5, Interval: 2 * time.Second},
+				Guild: Bucket{Burst: 20, Interval: 500 * time.Millisecond},
+			},
+			Commands: map[string]RateLimit{
+				"play": {
+					User:  Bucket{Burst: 3, Interval: 5 * time.Second},
+					Guild: Bucket{Burst: 10, Interval: 2 * time.Second},
+				},
+				"skip": {
+					User:  Bucket{Burst: 2, Interval: 3 * time.Second},
+					Guild: Bucket{Burst: 5, Interval: 2 * time.Second},
+				},
+				"player": {
+					User:  Bucket{Burst: 1, Interval: 5 * time.Second},
+					Guild: Bucket{Burst: 3, Interval: 5 * time.Second},
+				},
+			},
+		},
 	}
-	return id
 }
 
-// mustParseInt parses an integer from an environment variable.
-func mustParseInt(key string) int {
-	valueStr := mustGetEnv(key)
-	value, err := strconv.Atoi(valueStr)
+// LoadConfig builds the bot's configuration, validates it and makes it the active configuration.
+// Settings are layered from the built-in defaults, the config file at path (if not empty), the section
+// of the file's profiles block for the selected profile, and finally the environment variables. The
+// profile is taken from the profile argument, then BOT_PROFILE, then the file's profile key. All problems
+// found are returned together.
+func LoadConfig(path, profile string) error {
+	log.Println("Starting to load configuration...")
+
+	cfg, err := load(path, profile)
 	if err != nil {
-		log.Fatalf("Invalid %s: %v", key, err)
+		return err
 	}
-	return value
+
+	source.path, source.profile = path, profile
+	current.Store(cfg)
+	log.Printf("Configuration loaded successfully (profile %s)", cfg.Profile)
+	return nil
 }
 
-// loadAndValidateDiscordToken handles Discord token retrieval and basic validation.
-func loadAndValidateDiscordToken() string {
-	token := strings.TrimSpace(mustGetEnv("DISCORD_TOKEN"))
+// load builds and validates a configuration without activating it.
+func load(path, profile string) (*Config, error) {
+	if profile == "" {
+		profile = os.Getenv("BOT_PROFILE")
+	}
+
+	cfg := defaultConfig()
+	var errs []error
+	if path != "" {
+		if err := loadFile(path, profile, &cfg); err != nil {
+			errs = append(errs, err)
+		} else {
+			log.Printf("Loaded configuration file %s", path)
+		}
+	}
+	errs = append(errs, applyEnv(&cfg)...)
+	if profile != "" {
+		cfg.Profile = profile
+	}
+	errs = append(errs, cfg.Validate())
 
-	log.Printf("Discord token length: %d", len(token))
-	log.Printf("Discord token first 10 characters: %s", token[:10])
-	log.Printf("Discord token last 10 characters: %s", token[len(token)-10:])
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return &cfg, nil
+}
 
-	parts := strings.Split(token, ".")
+// validateDiscordToken performs basic sanity checks on the Discord token without revealing any part of it.
+func validateDiscordToken(token Secret) error {
+	parts := strings.Split(token.Value(), ".")
 	if len(parts) != 3 {
-		log.Fatalf("Token does not have the expected number of parts (expected 3, got %d)", len(parts))
+		return fmt.Errorf("discord.token does not have the expected number of parts (expected 3, got %d)", len(parts))
 	}
 
+	// The decoding error would quote the offending bytes, so it is not wrapped
 	if _, err := base64.RawStdEncoding.DecodeString(parts[0]); err != nil {
-		log.Fatalf("Failed to decode base64 part of token: %v", err)
+		return fmt.Errorf("discord.token is malformed: its first part is not valid base64")
 	}
-
-	log.Println("Token passed basic validation checks")
-	return token
+	return nil
 }
 
-// ValidateConfig checks if all critical configuration fields are set.
-func ValidateConfig() error {
-	if AppConfig.DBHost == "" || AppConfig.DBPort == "" || AppConfig.DBUser == "" ||
-		AppConfig.DBPassword == "" || AppConfig.DBName == "" || AppConfig.DiscordToken == "" ||
-		AppConfig.LavalinkHost == "" || AppConfig.LavalinkPort == "" || AppConfig.LavalinkPassword == "" {
-		return fmt.Errorf("one or more required environment variables are missing")
+// Validate checks the configuration and reports every problem found, naming the config file key
+// and the environment variable that set each field.
+func (c Config) Validate() error {
+	var errs []error
+	required := func(value, key, env string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s (%s) is required", key, env))
+		}
 	}
-	return nil
+
+	if c.Profile != ProfileDev && c.Profile != ProfileProd {
+		errs = append(errs, fmt.Errorf("profile %q is unknown, expected %q or %q", c.Profile, ProfileDev, ProfileProd))
+	}
+
+	required(c.Discord.Token.Value(), "discord.token", "DISCORD_TOKEN")
+	if c.Discord.Token != "" {
+		if err := validateDiscordToken(c.Discord.Token); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !c.Modules.Music && !c.Modules.Starboard {
+		errs = append(errs, fmt.Errorf("no modules are enabled (MUSIC_ENABLED, STARBOARD_ENABLED)"))
+	}
+
+	// The starboard keeps its counts in the database; music only uses it when it is configured
+	if c.Modules.Starboard || c.DatabaseEnabled() {
+		required(c.Database.Host, "database.host", "DB_HOST")
+		required(c.Database.Port, "database.port", "DB_PORT")
+		required(c.Database.User, "database.user", "DB_USER")
+		required(c.Database.Password.Value(), "database.password", "DB_PASSWORD")
+		required(c.Database.Name, "database.name", "DB_NAME")
+	}
+
+	if c.Modules.Starboard {
+		if c.Starboard.ChannelID == 0 {
+			errs = append(errs, fmt.Errorf("starboard.channel_id (STARBOARD_CHANNEL_ID) is required"))
+		}
+		if c.Starboard.Threshold < 1 {
+			errs = append(errs, fmt.Errorf("starboard.threshold (STAR_THRESHOLD) must be at least 1"))
+		}
+		if c.Starboard.Color < 0 || c.Starboard.Color > 0xFFFFFF {
+			errs = append(errs, fmt.Errorf("starboard.color (STARBOARD_COLOR) must be an RGB value between 0x000000 and 0xFFFFFF"))
+		}
+	}
+
+	if c.RateLimits.Default.negative() {
+		errs = append(errs, fmt.Errorf("rate_limits.default must not be negative"))
+	}
+	for name, limit := range c.RateLimits.Commands {
+		if limit.negative() {
+			errs = append(errs, fmt.Errorf("rate_limits.commands.%s must not be negative", name))
+		}
+	}
+
+	if c.Modules.Music {
+		if c.Music.IdleTimeout < 0 || c.Music.EmptyChannelTimeout < 0 || c.Lavalink.ResumeTimeout < 0 {
+			errs = append(errs, fmt.Errorf("timeouts must not be negative"))
+		}
+
+		if len(c.Lavalink.Nodes) == 0 {
+			errs = append(errs, fmt.Errorf("at least one Lavalink node must be configured"))
+		}
+		names := make(map[string]bool)
+		for i, node := range c.Lavalink.Nodes {
+			if node.Name == "" || node.Address == "" {
+				errs = append(errs, fmt.Errorf("lavalink node %d is missing a name or address", i))
+			}
+			if names[node.Name] {
+				errs = append(errs, fmt.Errorf("lavalink node name %q is used more than once", node.Name))
+			}
+			names[node.Name] = true
+		}
+	}
+	return errors.Join(errs...)
 }
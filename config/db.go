@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -13,15 +14,17 @@ import (
 // DB holds the global connection pool to the PostgreSQL database.
 var DB *sql.DB
 
-// ConnectDB initializes the database connection using environment variables and establishes a connection pool.
-func ConnectDB() {
+// ConnectDB initializes the database connection from the database section of the active configuration and establishes a connection pool.
+// ctx bounds the initial connection check.
+func ConnectDB(ctx context.Context) {
 	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		Get().Database.Host,
+		Get().Database.Port,
+		Get().Database.User,
+		Get().Database.Password.Value(),
+		Get().Database.Name,
+		Get().Database.SSLMode,
 	)
 
 	var err error
@@ -30,7 +33,7 @@ func ConnectDB() {
 		log.Fatalf("Cannot open the database: %v", err)
 	}
 
-	err = DB.Ping()
+	err = DB.PingContext(ctx)
 	if err != nil {
 		log.Fatalf("Cannot ping the database: %v", err)
 	}
@@ -44,31 +47,53 @@ func ConnectDB() {
 	log.Println("Database schema initialized")
 }
 
+// schemas lists the tables owned by the bot and the DDL script that creates each of them.
+var schemas = []struct {
+	table  string
+	script string
+}{
+	{table: "starboard", script: "SQL/starboard-ddl.sql"},
+	{table: "favourites", script: "SQL/favourites-ddl.sql"},
+	{table: "guild_settings", script: "SQL/guild-settings-ddl.sql"},
+	{table: "guild_prefixes", script: "SQL/guild-prefixes-ddl.sql"},
+	{table: "player_state", script: "SQL/player-state-ddl.sql"},
+	{table: "lavalink_sessions", script: "SQL/lavalink-sessions-ddl.sql"},
+}
+
 // Initialize the database schema
 func initDBSchema() error {
-	// Check if the starboard table already exists
+	for _, schema := range schemas {
+		if err := initTable(schema.table, schema.script); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// initTable runs the DDL script for a table unless the table already exists.
+func initTable(table, scriptPath string) error {
 	var exists bool
-	err := DB.QueryRow("SELECT EXISTS (SELECT FROM information_schema.tables WHERE table_name = 'starboard')").Scan(&exists)
+	err := DB.QueryRow("SELECT EXISTS (SELECT FROM information_schema.tables WHERE table_name = $1)", table).Scan(&exists)
 	if err != nil {
-		return fmt.Errorf("failed to check if starboard table exists: %v", err)
+		return fmt.Errorf("failed to check if %s table exists: %v", table, err)
 	}
 
 	if exists {
-		log.Println("Starboard table already exists, skipping initialization")
+		log.Printf("%s table already exists, skipping initialization", table)
 		return nil
 	}
 
 	// Read and execute the SQL script only if the table doesn't exist
-	script, err := os.ReadFile("SQL/starboard-ddl.sql")
+	script, err := os.ReadFile(scriptPath)
 	if err != nil {
-		return fmt.Errorf("failed to read starboard-ddl.sql: %v", err)
+		return fmt.Errorf("failed to read %s: %v", scriptPath, err)
 	}
 
 	_, err = DB.Exec(string(script))
 	if err != nil {
-		return fmt.Errorf("failed to execute starboard-ddl.sql: %v", err)
+		return fmt.Errorf("failed to execute %s: %v", scriptPath, err)
 	}
 
-	log.Println("Starboard table created successfully")
+	log.Printf("%s table created successfully", table)
 	return nil
 }
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// envOverrides collects the problems found while applying environment variables so they can be reported together.
+type envOverrides struct {
+	errs []error
+}
+
+// applyEnv overrides cfg with every environment variable that is set and returns the invalid ones.
+func applyEnv(cfg *Config) []error {
+	env := &envOverrides{}
+
+	env.bool("MUSIC_ENABLED", &cfg.Modules.Music)
+	env.bool("STARBOARD_ENABLED", &cfg.Modules.Starboard)
+
+	env.secret("DISCORD_TOKEN", &cfg.Discord.Token)
+	env.snowflake("DISCORD_DEV_GUILD_ID", &cfg.Discord.DevGuildID)
+
+	env.string("DB_HOST", &cfg.Database.Host)
+	env.string("DB_PORT", &cfg.Database.Port)
+	env.string("DB_USER", &cfg.Database.User)
+	env.secret("DB_PASSWORD", &cfg.Database.Password)
+	env.string("DB_NAME", &cfg.Database.Name)
+	env.string("DB_SSLMODE", &cfg.Database.SSLMode)
+
+	env.snowflake("STARBOARD_CHANNEL_ID", &cfg.Starboard.ChannelID)
+	env.int("STAR_THRESHOLD", &cfg.Starboard.Threshold)
+	env.int("STARBOARD_COLOR", &cfg.Starboard.Color)
+
+	env.duration("IDLE_TIMEOUT", &cfg.Music.IdleTimeout)
+	env.duration("EMPTY_CHANNEL_TIMEOUT", &cfg.Music.EmptyChannelTimeout)
+	env.bool("PAUSE_ON_EMPTY", &cfg.Music.PauseOnEmpty)
+	env.snowflakes("MUSIC_CHANNEL_IDS", &cfg.Music.Channels)
+
+	env.duration("LAVALINK_RESUME_TIMEOUT", &cfg.Lavalink.ResumeTimeout)
+	env.lavalinkNodes(&cfg.Lavalink.Nodes)
+
+	env.string("DEBUG_ADDR", &cfg.Debug.Addr)
+
+	return env.errs
+}
+
+func (e *envOverrides) lookup(key string) (string, bool) {
+	value := os.Getenv(key)
+	return value, value != ""
+}
+
+func (e *envOverrides) invalid(key string, err error) {
+	e.errs = append(e.errs, fmt.Errorf("invalid %s: %w", key, err))
+}
+
+func (e *envOverrides) string(key string, target *string) {
+	if value, ok := e.lookup(key); ok {
+		*target = value
+	}
+}
+
+// secret reads a secret from key or, if that is not set, from the file named by key_FILE,
+// which is how Docker and Kubernetes secrets are usually mounted.
+func (e *envOverrides) secret(key string, target *Secret) {
+	if value, ok := e.secretValue(key); ok {
+		*target = value
+	}
+}
+
+func (e *envOverrides) secretValue(key string) (Secret, bool) {
+	if value, ok := e.lookup(key); ok {
+		return Secret(value), true
+	}
+	path, ok := e.lookup(key + "_FILE")
+	if !ok {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		e.invalid(key+"_FILE", err)
+		return "", false
+	}
+	return Secret(strings.TrimSpace(string(data))), true
+}
+
+func (e *envOverrides) int(key string, target *int) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	// Base 0 accepts hexadecimal values such as 0xFFAC33 for colours
+	parsed, err := strconv.ParseInt(value, 0, 0)
+	if err != nil {
+		e.invalid(key, err)
+		return
+	}
+	*target = int(parsed)
+}
+
+func (e *envOverrides) bool(key string, target *bool) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		e.invalid(key, err)
+		return
+	}
+	*target = parsed
+}
+
+// duration parses a duration such as "5m". A value of "0" disables the corresponding timer.
+func (e *envOverrides) duration(key string, target *time.Duration) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		e.invalid(key, err)
+		return
+	}
+	*target = parsed
+}
+
+func (e *envOverrides) snowflake(key string, target *snowflake.ID) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	parsed, err := snowflake.Parse(value)
+	if err != nil {
+		e.invalid(key, err)
+		return
+	}
+	*target = parsed
+}
+
+// snowflakes reads a comma separated list of IDs.
+func (e *envOverrides) snowflakes(key string, target *[]snowflake.ID) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	var ids []snowflake.ID
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := snowflake.Parse(field)
+		if err != nil {
+			e.invalid(key, err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	*target = ids
+}
+
+// lavalinkNodes reads the Lavalink nodes from LAVALINK_NODES, a JSON list of nodes. If it is not set
+// but SERVER_ADDRESS is, a single node named "default" is built from SERVER_ADDRESS, SERVER_PORT and
+// LAVALINK_SERVER_PASSWORD. Either replaces the nodes of the config file.
+func (e *envOverrides) lavalinkNodes(target *[]LavalinkNode) {
+	if raw, ok := e.lookup("LAVALINK_NODES"); ok {
+		var nodes []LavalinkNode
+		if err := json.Unmarshal([]byte(raw), &nodes); err != nil {
+			e.invalid("LAVALINK_NODES", err)
+			return
+		}
+		*target = nodes
+		return
+	}
+
+	address, ok := e.lookup("SERVER_ADDRESS")
+	if !ok {
+		return
+	}
+	port, ok := e.lookup("SERVER_PORT")
+	if !ok {
+		e.errs = append(e.errs, fmt.Errorf("SERVER_PORT is required when SERVER_ADDRESS is set"))
+		return
+	}
+	password, _ := e.secretValue("LAVALINK_SERVER_PASSWORD")
+	*target = []LavalinkNode{{
+		Name:     "default",
+		Address:  address + ":" + port,
+		Password: password,
+	}}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the layout of the config file: the regular settings plus per-profile overrides.
+type fileConfig struct {
+	Config   `yaml:",inline"`
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+}
+
+// loadFile decodes the YAML config file at path onto cfg and then applies the overrides of the
+// selected profile. If profile is empty, the profile key of the file decides.
+func loadFile(path, profile string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	file := fileConfig{Config: *cfg}
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("cannot parse config file %s: %w", path, err)
+	}
+	*cfg = file.Config
+
+	if profile == "" {
+		profile = cfg.Profile
+	}
+	cfg.Profile = profile
+
+	overrides, ok := file.Profiles[profile]
+	if !ok {
+		return nil
+	}
+	if err = overrides.Decode(cfg); err != nil {
+		return fmt.Errorf("cannot parse profile %q of config file %s: %w", profile, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReloadFunc is called after a reload with the previous and the new configuration.
+type ReloadFunc func(old, new *Config)
+
+var (
+	reloadMu    sync.Mutex
+	subscribers []ReloadFunc
+)
+
+// OnReload registers f to be called whenever a reload changes the configuration.
+func OnReload(f ReloadFunc) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	subscribers = append(subscribers, f)
+}
+
+// restartOnly returns the settings that are only read at startup. Changing them requires a restart.
+func restartOnly(c *Config) Config {
+	return Config{
+		Profile:  c.Profile,
+		Modules:  c.Modules,
+		Discord:  c.Discord,
+		Database: c.Database,
+		Debug:    c.Debug,
+	}
+}
+
+// Reload loads the configuration again from the same file and environment and activates it. An invalid
+// configuration is rejected and the active one is kept. Changes to settings that are only read at startup
+// are logged and ignored until the next restart. Subscribers are called after the lock is released, so
+// they may register further subscribers or take their time without blocking other reloads.
+func Reload() error {
+	old, next, notify, err := activate()
+	if err != nil {
+		return err
+	}
+	for _, f := range notify {
+		f(old, next)
+	}
+	return nil
+}
+
+// activate loads and stores the new configuration. It returns the previous and the new configuration
+// together with the subscribers to notify, which are none if nothing changed.
+func activate() (*Config, *Config, []ReloadFunc, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	next, err := load(source.path, source.profile)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("reload rejected, keeping the running configuration: %w", err)
+	}
+
+	old := Get()
+	oldStatic, nextStatic := restartOnly(old), restartOnly(next)
+	for _, change := range diff("", reflect.ValueOf(oldStatic), reflect.ValueOf(nextStatic)) {
+		log.Printf("Configuration change requires a restart and was not applied: %s", change)
+	}
+	next.Profile, next.Modules, next.Discord, next.Database, next.Debug = old.Profile, old.Modules, old.Discord, old.Database, old.Debug
+
+	// The file was validated with its own modules, so a section of a module it turns off was not checked
+	if err := next.Validate(); err != nil {
+		return nil, nil, nil, fmt.Errorf("reload rejected, keeping the running configuration: invalid with the settings that need a restart:\n%w", err)
+	}
+
+	changes := diff("", reflect.ValueOf(*old), reflect.ValueOf(*next))
+	if len(changes) == 0 {
+		log.Println("Configuration reloaded, nothing changed")
+		return old, next, nil, nil
+	}
+
+	current.Store(next)
+	for _, change := range changes {
+		log.Printf("Configuration changed: %s", change)
+	}
+	return old, next, slices.Clone(subscribers), nil
+}
+
+// diff lists the settings that differ between two configs as "key: old -> new", named by their config
+// file keys. Secrets print redacted, so only the fact that they changed is visible.
+func diff(prefix string, old, next reflect.Value) []string {
+	var changes []string
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		oldValue, nextValue := old.Field(i), next.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			changes = append(changes, diff(key, oldValue, nextValue)...)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Interface(), nextValue.Interface()) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", key, oldValue.Interface(), nextValue.Interface()))
+		}
+	}
+	return changes
+}
+
+// Watch reloads the configuration whenever the config file changes, checking every interval, and
+// whenever a signal arrives on signals (usually SIGHUP) until ctx is done.
+func Watch(ctx context.Context, interval time.Duration, signals <-chan os.Signal) {
+	lastMod := fileVersion(source.path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			log.Println("Received reload signal")
+		case <-ticker.C:
+			if source.path == "" {
+				continue
+			}
+			mod := fileVersion(source.path)
+			if mod == lastMod {
+				continue
+			}
+			lastMod = mod
+			log.Printf("Configuration file %s changed", source.path)
+		}
+
+		if err := Reload(); err != nil {
+			log.Printf("Failed to reload configuration: %v", err)
+		}
+	}
+}
+
+// fileVersion identifies the current contents of a file by its modification time and size.
+func fileVersion(path string) string {
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size())
+}
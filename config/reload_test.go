@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testConfigFile is a valid configuration with both modules enabled.
+const testConfigFile = `
+discord:
+  token: MTIzNDU2Nzg5MDEyMzQ1Njc4.GhIjKl.test-token
+database:
+  host: db
+  user: bot
+  password: test-password
+  name: bot
+lavalink:
+  nodes:
+    - name: default
+      address: lavalink:2333
+starboard:
+  channel_id: 1282793245289484420
+  threshold: 3
+`
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		wantErr       bool
+		wantThreshold int
+	}{
+		{
+			name:          "live setting changed",
+			file:          testConfigFile + "music:\n  idle_timeout: 1m\n",
+			wantThreshold: 3,
+		},
+		{
+			name: "starboard section removed while the module keeps running",
+			file: `
+modules:
+  starboard: false
+discord:
+  token: MTIzNDU2Nzg5MDEyMzQ1Njc4.GhIjKl.test-token
+lavalink:
+  nodes:
+    - name: default
+      address: lavalink:2333
+`,
+			wantErr:       true,
+			wantThreshold: 3,
+		},
+		{
+			name:          "invalid file",
+			file:          testConfigFile + "starboard:\n  threshold: 0\n",
+			wantErr:       true,
+			wantThreshold: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			writeConfigFile(t, path, testConfigFile)
+			if err := LoadConfig(path, ""); err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			loaded := Get()
+
+			writeConfigFile(t, path, test.file)
+			err := Reload()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Reload() error = %v, want error: %t", err, test.wantErr)
+			}
+			if test.wantErr && Get() != loaded {
+				t.Error("Reload() replaced the running configuration although it was rejected")
+			}
+			if err := Get().Validate(); err != nil {
+				t.Errorf("running configuration is invalid: %v", err)
+			}
+			if got := Get().Starboard.Threshold; got != test.wantThreshold {
+				t.Errorf("starboard.threshold = %d, want %d", got, test.wantThreshold)
+			}
+		})
+	}
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("error writing config file: %v", err)
+	}
+}
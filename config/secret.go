@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// redacted replaces the value of a Secret wherever it is printed.
+const redacted = "[REDACTED]"
+
+// Secret is a configuration value such as a token or password. It hides its value from fmt, slog
+// and encoders so it cannot end up in logs by accident; use Value to read it.
+type Secret string
+
+// Value returns the secret in clear.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String returns a placeholder instead of the secret.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString keeps the secret out of %#v output.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+// Format keeps the secret out of every fmt verb, including %x and %q.
+func (s Secret) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'q':
+		fmt.Fprintf(f, "%q", s.String())
+	case 'v':
+		if f.Flag('#') {
+			fmt.Fprint(f, s.GoString())
+			return
+		}
+		fmt.Fprint(f, s.String())
+	default:
+		fmt.Fprint(f, s.String())
+	}
+}
+
+// LogValue keeps the secret out of slog output.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// MarshalText keeps the secret out of JSON and other text encodings.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// MarshalYAML keeps the secret out of YAML output.
+func (s Secret) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	testToken      = "MTIzNDU2Nzg5MDEyMzQ1Njc4.GhIjKl.raw-discord-token"
+	testDBPassword = "raw-database-password"
+	testNodeSecret = "raw-lavalink-password"
+)
+
+func TestSecretMethods(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret Secret
+		want   string
+	}{
+		{name: "set", secret: Secret(testToken), want: redacted},
+		{name: "empty", secret: "", want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.secret.String(); got != test.want {
+				t.Errorf("String() = %q, want %q", got, test.want)
+			}
+			if got, want := test.secret.GoString(), fmt.Sprintf("config.Secret(%q)", test.want); got != want {
+				t.Errorf("GoString() = %q, want %q", got, want)
+			}
+			if got := test.secret.LogValue(); got.Kind() != slog.KindString || got.String() != test.want {
+				t.Errorf("LogValue() = %v, want %q", got, test.want)
+			}
+			text, err := test.secret.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error: %v", err)
+			}
+			if string(text) != test.want {
+				t.Errorf("MarshalText() = %q, want %q", text, test.want)
+			}
+			if got := test.secret.Value(); got != string(test.secret) {
+				t.Errorf("Value() = %q, want %q", got, string(test.secret))
+			}
+		})
+	}
+}
+
+func TestSecretFormatVerbs(t *testing.T) {
+	secret := Secret(testToken)
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X", "%10s", "%-10v"} {
+		t.Run(verb, func(t *testing.T) {
+			assertNoSecrets(t, fmt.Sprintf(verb, secret))
+		})
+	}
+}
+
+func TestConfigDumpHidesSecrets(t *testing.T) {
+	cfg := &Config{
+		Profile:  ProfileProd,
+		Discord:  DiscordConfig{Token: testToken},
+		Database: DatabaseConfig{Host: "db", Port: "5432", User: "bot", Password: testDBPassword, Name: "bot"},
+		Lavalink: LavalinkConfig{Nodes: []LavalinkNode{
+			{Name: "default", Address: "lavalink:2333", Password: testNodeSecret},
+		}},
+	}
+
+	dumps := map[string]func(t *testing.T) string{
+		"fmt %v": func(t *testing.T) string {
+			return fmt.Sprintf("%v", cfg)
+		},
+		"fmt %+v": func(t *testing.T) string {
+			return fmt.Sprintf("%+v", *cfg)
+		},
+		"fmt %#v": func(t *testing.T) string {
+			return fmt.Sprintf("%#v", *cfg)
+		},
+		"slog text": func(t *testing.T) string {
+			var out bytes.Buffer
+			slog.New(slog.NewTextHandler(&out, nil)).Info("config", "config", cfg, "token", cfg.Discord.Token)
+			return out.String()
+		},
+		"slog json": func(t *testing.T) string {
+			var out bytes.Buffer
+			slog.New(slog.NewJSONHandler(&out, nil)).Info("config", "config", cfg, "token", cfg.Discord.Token)
+			return out.String()
+		},
+		"json": func(t *testing.T) string {
+			out, err := json.Marshal(cfg)
+			if err != nil {
+				t.Fatalf("json.Marshal error: %v", err)
+			}
+			return string(out)
+		},
+		"yaml": func(t *testing.T) string {
+			out, err := yaml.Marshal(cfg)
+			if err != nil {
+				t.Fatalf("yaml.Marshal error: %v", err)
+			}
+			return string(out)
+		},
+	}
+
+	for name, dump := range dumps {
+		t.Run(name, func(t *testing.T) {
+			out := dump(t)
+			assertNoSecrets(t, out)
+			if !strings.Contains(out, redacted) {
+				t.Errorf("dump does not show that secrets were redacted:\n%s", out)
+			}
+		})
+	}
+}
+
+// secretName matches the names of config fields that hold credentials.
+var secretName = regexp.MustCompile(`(?i)token|password|secret|dsn`)
+
+// TestSecretFieldsUseSecret fails on any config field named like a credential that is not a Secret, as
+// such a field would be printed in clear.
+func TestSecretFieldsUseSecret(t *testing.T) {
+	secretType := reflect.TypeOf(Secret(""))
+	visited := make(map[reflect.Type]bool)
+
+	var walk func(typ reflect.Type, path string)
+	walk = func(typ reflect.Type, path string) {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			walk(typ.Elem(), path)
+			return
+		case reflect.Struct:
+		default:
+			return
+		}
+		if visited[typ] {
+			return
+		}
+		visited[typ] = true
+
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			fieldPath := path + "." + field.Name
+			if secretName.MatchString(field.Name) && field.Type != secretType {
+				t.Errorf("%s is a %s, but credentials must be a config.Secret", fieldPath, field.Type)
+			}
+			walk(field.Type, fieldPath)
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "Config")
+}
+
+// logFuncs are the functions and methods of log and slog that write a log record.
+var logFuncs = map[string]bool{
+	"Print": true, "Printf": true, "Println": true,
+	"Fatal": true, "Fatalf": true, "Fatalln": true,
+	"Panic": true, "Panicf": true, "Panicln": true,
+	"Debug": true, "Info": true, "Warn": true, "Error": true, "Log": true, "LogAttrs": true,
+	"DebugContext": true, "InfoContext": true, "WarnContext": true, "ErrorContext": true,
+}
+
+// TestSecretValuesNotLogged fails on any call to a log or slog function whose arguments read a secret in
+// clear with Value. It looks through the source of the whole module.
+func TestSecretValuesNotLogged(t *testing.T) {
+	fset := token.NewFileSet()
+	err := filepath.WalkDir("..", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != ".." && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || !isLogCall(call) {
+				return true
+			}
+			for _, arg := range call.Args {
+				ast.Inspect(arg, func(node ast.Node) bool {
+					if value, ok := node.(*ast.CallExpr); ok && isValueCall(value) {
+						t.Errorf("%s: the result of Value() is logged, which prints the secret in clear", fset.Position(value.Pos()))
+					}
+					return true
+				})
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error reading the module source: %v", err)
+	}
+}
+
+// isLogCall reports whether call writes a log record, like log.Printf or slog.Info and their methods on
+// a logger.
+func isLogCall(call *ast.CallExpr) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !logFuncs[selector.Sel.Name] {
+		return false
+	}
+	if ident, ok := selector.X.(*ast.Ident); ok {
+		return ident.Name == "log" || ident.Name == "slog" || strings.Contains(strings.ToLower(ident.Name), "logger")
+	}
+	return false
+}
+
+// isValueCall reports whether call is a call of a Value method without arguments, like
+// cfg.Discord.Token.Value().
+func isValueCall(call *ast.CallExpr) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && selector.Sel.Name == "Value" && len(call.Args) == 0
+}
+
+// assertNoSecrets fails the test if out contains any of the raw secrets or their hex encoding.
+func assertNoSecrets(t *testing.T, out string) {
+	t.Helper()
+	for _, secret := range []string{testToken, testDBPassword, testNodeSecret} {
+		for _, form := range []string{secret, fmt.Sprintf("%x", secret), fmt.Sprintf("%X", secret)} {
+			if strings.Contains(out, form) {
+				t.Errorf("output leaks secret %q:\n%s", secret, out)
+			}
+		}
+	}
+}
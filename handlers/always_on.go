@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"unccord-bot-go/i18n"
+	"unccord-bot-go/storage"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// alwaysOnRejoinDelay is how long the bot waits before rejoining its 24/7 channel after being disconnected.
+const alwaysOnRejoinDelay = 5 * time.Second
+
+func (h *Handler) alwaysOnSettings(guildID snowflake.ID) (storage.AlwaysOnSettings, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	settings, ok := h.alwaysOn[guildID]
+	return settings, ok
+}
+
+func (h *Handler) isAlwaysOn(guildID snowflake.ID) bool {
+	_, ok := h.alwaysOnSettings(guildID)
+	return ok
+}
+
+// loadAlwaysOn loads the 24/7 settings of a guild and rejoins its channel if 24/7 mode is on.
+func (h *Handler) loadAlwaysOn(ctx context.Context, guildID snowflake.ID) {
+	settings, ok, err := h.Store.AlwaysOn(ctx, guildID)
+	if err != nil {
+		slog.Error("Failed to load 24/7 settings", slog.Any("err", err), "guildID", guildID)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	h.alwaysOn[guildID] = settings
+	h.mu.Unlock()
+
+	if err := h.joinAlwaysOn(ctx, guildID); err != nil {
+		slog.Error("Failed to join 24/7 channel", slog.Any("err", err), "guildID", guildID)
+	}
+}
+
+// joinAlwaysOn joins the 24/7 channel of a guild and starts the playlist if nothing is playing. While no
+// Lavalink node is connected, the channel is joined once one connects instead.
+func (h *Handler) joinAlwaysOn(ctx context.Context, guildID snowflake.ID) error {
+	settings, ok := h.alwaysOnSettings(guildID)
+	if !ok {
+		return nil
+	}
+
+	if !h.musicAvailable() {
+		slog.Info("No Lavalink node connected, joining 24/7 channel once one is", "guildID", guildID)
+		h.mu.Lock()
+		h.pendingRejoin[guildID] = struct{}{}
+		h.mu.Unlock()
+		return nil
+	}
+
+	if err := h.Client.UpdateVoiceState(ctx, guildID, &settings.ChannelID, false, false); err != nil {
+		return err
+	}
+	slog.Info("Joined 24/7 channel", "guildID", guildID, "channelID", settings.ChannelID)
+
+	player, err := h.playerFor(guildID, settings.ChannelID)
+	if err != nil {
+		return err
+	}
+	if player.Track() != nil {
+		return nil
+	}
+	h.playNextTrack(ctx, guildID)
+	return nil
+}
+
+// rejoinAlwaysOn schedules a rejoin of the 24/7 channel after the bot was disconnected.
+func (h *Handler) rejoinAlwaysOn(guildID snowflake.ID) {
+	if !h.isAlwaysOn(guildID) {
+		return
+	}
+
+	slog.Info("Disconnected from 24/7 channel, rejoining", "guildID", guildID, "delay", alwaysOnRejoinDelay)
+	time.AfterFunc(alwaysOnRejoinDelay, func() {
+		h.runTask("24/7 rejoin", func(ctx context.Context) {
+			if selfState, ok := h.Client.Caches().VoiceState(guildID, h.Client.ApplicationID()); ok && selfState.ChannelID != nil {
+				return
+			}
+			if err := h.joinAlwaysOn(ctx, guildID); err != nil {
+				slog.Error("Failed to rejoin 24/7 channel", slog.Any("err", err), "guildID", guildID)
+			}
+		}, "guildID", guildID)
+	})
+}
+
+// refillFromPlaylist queues the tracks of the guild's 24/7 playlist. It reports whether any tracks were added.
+func (h *Handler) refillFromPlaylist(ctx context.Context, guildID snowflake.ID) bool {
+	settings, ok := h.alwaysOnSettings(guildID)
+	if !ok || settings.Playlist == "" {
+		return false
+	}
+
+	tracks, err := h.loadTracks(ctx, settings.Playlist)
+	if err != nil {
+		slog.Error("Failed to load 24/7 playlist", slog.Any("err", err), "guildID", guildID)
+		return false
+	}
+
+	h.Queues.Get(guildID).Add(tracks...)
+	slog.Info("Refilled queue from 24/7 playlist", "trackCount", len(tracks), "guildID", guildID)
+	return len(tracks) > 0
+}
+
+func (h *Handler) handleAlwaysOn(ctx context.Context, event *CommandEvent) {
+	data := event.SlashCommandInteractionData()
+	if data.SubCommandName == nil {
+		return
+	}
+
+	switch *data.SubCommandName {
+	case "on":
+		h.handleAlwaysOnEnable(ctx, event, data)
+	case "off":
+		h.handleAlwaysOnDisable(ctx, event)
+	}
+}
+
+func (h *Handler) handleAlwaysOnEnable(ctx context.Context, event *CommandEvent, data discord.SlashCommandInteractionData) {
+	guildID := *event.GuildID()
+	settings := storage.AlwaysOnSettings{ChannelID: data.Snowflake("channel")}
+	if playlist, ok := data.OptString("playlist"); ok {
+		settings.Playlist = playlist
+	}
+
+	response := deferResponse(ctx, event, false)
+	if err := h.Store.SetAlwaysOn(ctx, guildID, settings); err != nil {
+		response.EditError(fmt.Errorf("error saving 24/7 settings of guild %s: %w", guildID, err))
+		return
+	}
+
+	h.mu.Lock()
+	h.alwaysOn[guildID] = settings
+	h.mu.Unlock()
+	h.clearVoiceTimers(guildID)
+
+	response.EditEmbed(discord.NewEmbedBuilder().
+		SetTitle(i18n.Text(interactionLocale(event), "always_on.enabled.title")).
+		SetDescription(i18n.Text(interactionLocale(event), "always_on.enabled", settings.ChannelID)).
+		SetColor(ColorSuccess).
+		Build())
+
+	// Joining and loading the playlist can outlive the interaction, so they get their own context
+	// and report failures as a follow-up
+	go h.runTask("24/7 join", func(ctx context.Context) {
+		if err := h.joinAlwaysOn(ctx, guildID); err != nil {
+			response.FollowUp(discord.NewMessageCreateBuilder().
+				SetEmbeds(errorEmbed(interactionLocale(event), fmt.Errorf("error joining 24/7 channel of guild %s: %w", guildID, err))).
+				Build())
+		}
+	}, "guildID", guildID)
+}
+
+func (h *Handler) handleAlwaysOnDisable(ctx context.Context, event *CommandEvent) {
+	guildID := *event.GuildID()
+	response := deferResponse(ctx, event, false)
+	if err := h.Store.ClearAlwaysOn(ctx, guildID); err != nil {
+		response.EditError(fmt.Errorf("error clearing 24/7 settings of guild %s: %w", guildID, err))
+		return
+	}
+
+	h.mu.Lock()
+	delete(h.alwaysOn, guildID)
+	h.mu.Unlock()
+
+	// Fall back to the regular idle rules
+	if player := h.Lavalink.ExistingPlayer(guildID); player == nil || player.Track() == nil {
+		h.startIdleTimer(guildID)
+	}
+	h.checkVoiceChannelEmpty(ctx, guildID)
+
+	response.EditEmbed(discord.NewEmbedBuilder().
+		SetDescription(i18n.Text(interactionLocale(event), "always_on.disabled")).
+		SetColor(ColorSuccess).
+		Build())
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"unccord-bot-go/i18n"
+	"unccord-bot-go/queue"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// autoplaySources returns the identifiers to search for tracks related to the given one, best match first.
+func autoplaySources(track lavalink.Track) []string {
+	var sources []string
+	if track.Info.SourceName == "youtube" {
+		// YouTube mixes are seeded by the video they start from
+		sources = append(sources, fmt.Sprintf("https://www.youtube.com/watch?v=%s&list=RD%s", track.Info.Identifier, track.Info.Identifier))
+	}
+	return append(sources, fmt.Sprintf("ytsearch:%s %s", track.Info.Author, track.Info.Title))
+}
+
+// autoplayTrack picks a track related to the last played one that is not in the recent history.
+func (h *Handler) autoplayTrack(ctx context.Context, guildID snowflake.ID) (lavalink.Track, bool) {
+	guildQueue := h.Queues.Get(guildID)
+	lastTrack, ok := guildQueue.LastPlayed()
+	if !guildQueue.Autoplay() || !ok {
+		return lavalink.Track{}, false
+	}
+
+	for _, source := range autoplaySources(lastTrack) {
+		tracks, err := h.loadTracks(ctx, source)
+		if err != nil {
+			slog.Warn("Failed to load autoplay candidates", slog.Any("err", err), "source", source, "guildID", guildID)
+			continue
+		}
+
+		for _, track := range tracks {
+			if track.Info.IsStream || guildQueue.RecentlyPlayed(track) {
+				continue
+			}
+
+			track, err = track.WithUserData(queue.TrackData{Autoplay: true})
+			if err != nil {
+				slog.Error("Failed to label autoplay track", slog.Any("err", err))
+				continue
+			}
+			slog.Info("Autoplay picked track", "title", track.Info.Title, "seed", lastTrack.Info.Title, "guildID", guildID)
+			return track, true
+		}
+	}
+
+	slog.Info("Autoplay found no new related tracks", "seed", lastTrack.Info.Title, "guildID", guildID)
+	return lavalink.Track{}, false
+}
+
+// trackLabel returns the title of a track, marked in locale if it was picked by autoplay.
+func trackLabel(locale discord.Locale, track lavalink.Track) string {
+	if queue.Data(track).Autoplay {
+		return i18n.Text(locale, "music.autoplay_label", track.Info.Title)
+	}
+	return track.Info.Title
+}
+
+func (h *Handler) handleAutoplay(ctx context.Context, event *CommandEvent) {
+	enabled := h.Queues.Get(*event.GuildID()).ToggleAutoplay()
+
+	id := "music.autoplay_disabled"
+	if enabled {
+		id = "music.autoplay_enabled"
+	}
+
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(discord.NewEmbedBuilder().
+			SetDescription(i18n.Text(interactionLocale(event), id)).
+			SetColor(ColorSuccess).
+			Build()).
+		Build()); err != nil {
+		slog.Error("Failed to send autoplay status", slog.Any("err", err))
+	}
+}
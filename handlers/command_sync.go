@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"unccord-bot-go/config"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// comparedCommandFields are the fields of a command definition that decide whether Discord's copy is out
+// of date. Everything else in Discord's response, like IDs and versions, is set by Discord.
+var comparedCommandFields = []string{
+	"type",
+	"name",
+	"name_localizations",
+	"description",
+	"description_localizations",
+	"options",
+	"default_member_permissions",
+	"contexts",
+	"nsfw",
+}
+
+// SyncCommands registers the commands of the enabled modules, skipping the update when Discord already
+// has them. With the dev profile and a dev guild configured the commands go to that guild, where changes
+// show up instantly. Otherwise they are registered globally and the dev guild's copies are removed so
+// they do not show up twice.
+func (h *Handler) SyncCommands(ctx context.Context, client bot.Client) error {
+	cfg := config.Get()
+	devGuildID := cfg.Discord.DevGuildID
+	if cfg.IsDev() {
+		if devGuildID == 0 {
+			slog.Warn("No dev guild configured, registering commands globally")
+		} else {
+			return h.syncGuildCommands(ctx, client, devGuildID, h.Commands())
+		}
+	}
+
+	if err := h.syncGlobalCommands(ctx, client, h.Commands()); err != nil {
+		return err
+	}
+	if devGuildID != 0 {
+		// The global commands are in place, so a failed cleanup only leaves duplicates behind
+		if err := h.syncGuildCommands(ctx, client, devGuildID, nil); err != nil {
+			slog.Warn("Failed to remove stale dev guild commands", slog.Any("err", err), "guildID", devGuildID)
+		}
+	}
+	return nil
+}
+
+func (h *Handler) syncGlobalCommands(ctx context.Context, client bot.Client, desired []discord.ApplicationCommandCreate) error {
+	existing, err := client.Rest().GetGlobalCommands(client.ApplicationID(), true, rest.WithCtx(ctx))
+	if err != nil {
+		return fmt.Errorf("error fetching global commands: %w", err)
+	}
+	upToDate, err := commandsMatch(desired, existing)
+	if err != nil {
+		return err
+	}
+	if upToDate {
+		slog.Info("Global commands are up to date", "commandCount", len(desired))
+		return nil
+	}
+
+	if _, err = client.Rest().SetGlobalCommands(client.ApplicationID(), desired, rest.WithCtx(ctx)); err != nil {
+		return fmt.Errorf("error registering global commands: %w", err)
+	}
+	slog.Info("Registered global commands", "commandCount", len(desired))
+	return nil
+}
+
+func (h *Handler) syncGuildCommands(ctx context.Context, client bot.Client, guildID snowflake.ID, desired []discord.ApplicationCommandCreate) error {
+	existing, err := client.Rest().GetGuildCommands(client.ApplicationID(), guildID, true, rest.WithCtx(ctx))
+	if err != nil {
+		return fmt.Errorf("error fetching guild commands: %w", err)
+	}
+	upToDate, err := commandsMatch(desired, existing)
+	if err != nil {
+		return err
+	}
+	if upToDate {
+		slog.Info("Guild commands are up to date", "guildID", guildID, "commandCount", len(desired))
+		return nil
+	}
+
+	if desired == nil {
+		// Discord expects an empty list rather than null to remove all commands
+		desired = []discord.ApplicationCommandCreate{}
+	}
+	if _, err = client.Rest().SetGuildCommands(client.ApplicationID(), guildID, desired, rest.WithCtx(ctx)); err != nil {
+		return fmt.Errorf("error registering guild commands: %w", err)
+	}
+	if len(desired) == 0 {
+		slog.Info("Removed stale guild commands", "guildID", guildID, "commandCount", len(existing))
+	} else {
+		slog.Info("Registered guild commands", "guildID", guildID, "commandCount", len(desired))
+	}
+	return nil
+}
+
+// commandsMatch reports whether the commands registered with Discord are the desired ones.
+func commandsMatch(desired []discord.ApplicationCommandCreate, existing []discord.ApplicationCommand) (bool, error) {
+	if len(desired) != len(existing) {
+		return false, nil
+	}
+
+	registered := make(map[commandKey]map[string]any, len(existing))
+	for _, cmd := range existing {
+		fields, err := commandFields(cmd)
+		if err != nil {
+			return false, err
+		}
+		registered[commandKey{commandType: cmd.Type(), name: cmd.Name()}] = fields
+	}
+
+	for _, cmd := range desired {
+		fields, err := commandFields(cmd)
+		if err != nil {
+			return false, err
+		}
+		if !reflect.DeepEqual(fields, registered[commandKey{commandType: cmd.Type(), name: cmd.CommandName()}]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// commandFields returns the compared fields of a command definition as they are sent to Discord. Empty
+// values are dropped, as Discord leaves out or nulls some unset fields.
+func commandFields(cmd any) (map[string]any, error) {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding command: %w", err)
+	}
+	var all map[string]any
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("error decoding command: %w", err)
+	}
+
+	fields := make(map[string]any, len(comparedCommandFields))
+	for _, key := range comparedCommandFields {
+		if value, ok := all[key]; ok && !isEmptyField(value) {
+			fields[key] = value
+		}
+	}
+	// Discord's answer does not tell unset permissions apart from "0", so both count as unset
+	if fields["default_member_permissions"] == "0" {
+		delete(fields, "default_member_permissions")
+	}
+	return fields, nil
+}
+
+func isEmptyField(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !value
+	case string:
+		return value == ""
+	case []any:
+		return len(value) == 0
+	case map[string]any:
+		return len(value) == 0
+	}
+	return false
+}
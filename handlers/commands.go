@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"unccord-bot-go/i18n"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/json"
+)
+
+// CommandHandler runs an application command.
+type CommandHandler func(ctx context.Context, event *CommandEvent)
+
+// CommandEvent is an application command to run. Slash commands typed as text commands with the guild's
+// prefix arrive as an interaction built from the message, whose responses are sent to the channel as
+// replies to the message instead.
+type CommandEvent struct {
+	*events.ApplicationCommandInteractionCreate
+	reply *messageReply // Replies to the message of a text command; nil for interactions
+}
+
+// Command declares an application command together with everything needed to run it.
+type Command struct {
+	// Type is the kind of command, a slash command if not set. User and message commands show up in the
+	// context menu of users and messages; they have no description or options.
+	Type        discord.ApplicationCommandType
+	Name        string
+	Description string
+	Options     []discord.ApplicationCommandOption
+	// Permissions the member needs to use the command. They are also the default member
+	// permissions, so Discord hides the command from members without them.
+	Permissions discord.Permissions
+	GuildOnly   bool
+	Music       bool // Needs a connected Lavalink node
+	Handler     CommandHandler
+}
+
+// Create returns the definition registered with Discord, with the translations of the catalog.
+func (c Command) Create() discord.ApplicationCommandCreate {
+	var permissions *json.Nullable[discord.Permissions]
+	if c.Permissions != 0 {
+		permissions = json.NewNullablePtr(c.Permissions)
+	}
+	var contexts []discord.InteractionContextType
+	if c.GuildOnly {
+		contexts = []discord.InteractionContextType{discord.InteractionContextTypeGuild}
+	}
+
+	id := commandLocalizationID(c.Name)
+	switch c.Type {
+	case discord.ApplicationCommandTypeUser:
+		return discord.UserCommandCreate{
+			Name:                     c.Name,
+			NameLocalizations:        i18n.Localizations(id + ".name"),
+			DefaultMemberPermissions: permissions,
+			Contexts:                 contexts,
+		}
+	case discord.ApplicationCommandTypeMessage:
+		return discord.MessageCommandCreate{
+			Name:                     c.Name,
+			NameLocalizations:        i18n.Localizations(id + ".name"),
+			DefaultMemberPermissions: permissions,
+			Contexts:                 contexts,
+		}
+	default:
+		return discord.SlashCommandCreate{
+			Name:                     c.Name,
+			Description:              c.Description,
+			DescriptionLocalizations: i18n.Localizations(id + ".description"),
+			Options:                  localizeOptions(id, c.Options),
+			DefaultMemberPermissions: permissions,
+			Contexts:                 contexts,
+		}
+	}
+}
+
+// commandKey identifies a command. Commands of different types may share a name.
+type commandKey struct {
+	commandType discord.ApplicationCommandType
+	name        string
+}
+
+// Middleware wraps the handler of a command, for example to check preconditions or log its use.
+type Middleware func(cmd Command, next CommandHandler) CommandHandler
+
+// CommandRegistry holds the application commands of the enabled modules and dispatches interactions to them.
+type CommandRegistry struct {
+	commands   []Command
+	byKey      map[commandKey]Command
+	middleware []Middleware
+}
+
+// NewCommandRegistry creates an empty registry.
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{byKey: make(map[commandKey]Command)}
+}
+
+// Register adds commands to the registry. It panics on a duplicate name or a command without a handler,
+// as both are programming errors.
+func (r *CommandRegistry) Register(commands ...Command) {
+	for _, cmd := range commands {
+		if cmd.Type == 0 {
+			cmd.Type = discord.ApplicationCommandTypeSlash
+		}
+		key := commandKey{commandType: cmd.Type, name: cmd.Name}
+		if _, ok := r.byKey[key]; ok {
+			panic(fmt.Sprintf("command %q registered twice", cmd.Name))
+		}
+		if cmd.Handler == nil {
+			panic(fmt.Sprintf("command %q has no handler", cmd.Name))
+		}
+		r.commands = append(r.commands, cmd)
+		r.byKey[key] = cmd
+	}
+}
+
+// Use appends middleware. The first middleware added runs first.
+func (r *CommandRegistry) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Commands returns the registered commands in registration order.
+func (r *CommandRegistry) Commands() []Command {
+	return slices.Clone(r.commands)
+}
+
+// Command returns the registered command with the given type and name.
+func (r *CommandRegistry) Command(commandType discord.ApplicationCommandType, name string) (Command, bool) {
+	cmd, ok := r.byKey[commandKey{commandType: commandType, name: name}]
+	return cmd, ok
+}
+
+// Definitions returns the definitions of the registered commands for registering them with Discord.
+func (r *CommandRegistry) Definitions() []discord.ApplicationCommandCreate {
+	definitions := make([]discord.ApplicationCommandCreate, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		definitions = append(definitions, cmd.Create())
+	}
+	return definitions
+}
+
+// Handle runs the command of an interaction through the middleware. Slash commands with options the
+// command does not declare come from an outdated registration and are rejected.
+func (r *CommandRegistry) Handle(ctx context.Context, event *CommandEvent) {
+	cmd, ok := r.byKey[commandKey{commandType: event.Data.Type(), name: event.Data.CommandName()}]
+	if !ok {
+		slog.Warn("Received unknown command", "command", event.Data.CommandName(), "type", event.Data.Type())
+		return
+	}
+
+	if data, ok := event.Data.(discord.SlashCommandInteractionData); ok {
+		if err := validateOptions(cmd, data); err != nil {
+			slog.Warn("Rejected command with invalid options", slog.Any("err", err), "command", cmd.Name)
+			replyError(event, errOutdatedCommand)
+			return
+		}
+	}
+
+	handler := cmd.Handler
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](cmd, handler)
+	}
+	handler(ctx, event)
+}
+
+// validateOptions checks that every option of an interaction is declared by the command with the same type.
+func validateOptions(cmd Command, data discord.SlashCommandInteractionData) error {
+	declared := cmd.Options
+	for _, name := range []*string{data.SubCommandGroupName, data.SubCommandName} {
+		if name == nil {
+			continue
+		}
+		option, ok := findOption(declared, *name)
+		if !ok {
+			return fmt.Errorf("undeclared subcommand %q", *name)
+		}
+		switch option := option.(type) {
+		case discord.ApplicationCommandOptionSubCommandGroup:
+			declared = nil
+			for _, subCommand := range option.Options {
+				declared = append(declared, subCommand)
+			}
+		case discord.ApplicationCommandOptionSubCommand:
+			declared = option.Options
+		default:
+			return fmt.Errorf("option %q is not a subcommand", *name)
+		}
+	}
+
+	for name, value := range data.Options {
+		option, ok := findOption(declared, name)
+		if !ok {
+			return fmt.Errorf("undeclared option %q", name)
+		}
+		if option.Type() != value.Type {
+			return fmt.Errorf("option %q has type %d, declared as %d", name, value.Type, option.Type())
+		}
+	}
+	return nil
+}
+
+func findOption(options []discord.ApplicationCommandOption, name string) (discord.ApplicationCommandOption, bool) {
+	for _, option := range options {
+		if option.OptionName() == name {
+			return option, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// optionAccessors maps the methods of discord.SlashCommandInteractionData that read an option by name to
+// the option types they can read.
+var optionAccessors = map[string][]discord.ApplicationCommandOptionType{
+	"String":         {discord.ApplicationCommandOptionTypeString},
+	"OptString":      {discord.ApplicationCommandOptionTypeString},
+	"Int":            {discord.ApplicationCommandOptionTypeInt},
+	"OptInt":         {discord.ApplicationCommandOptionTypeInt},
+	"Bool":           {discord.ApplicationCommandOptionTypeBool},
+	"OptBool":        {discord.ApplicationCommandOptionTypeBool},
+	"Float":          {discord.ApplicationCommandOptionTypeFloat},
+	"OptFloat":       {discord.ApplicationCommandOptionTypeFloat},
+	"User":           {discord.ApplicationCommandOptionTypeUser},
+	"OptUser":        {discord.ApplicationCommandOptionTypeUser},
+	"Member":         {discord.ApplicationCommandOptionTypeUser},
+	"OptMember":      {discord.ApplicationCommandOptionTypeUser},
+	"Role":           {discord.ApplicationCommandOptionTypeRole},
+	"OptRole":        {discord.ApplicationCommandOptionTypeRole},
+	"Channel":        {discord.ApplicationCommandOptionTypeChannel},
+	"OptChannel":     {discord.ApplicationCommandOptionTypeChannel},
+	"Attachment":     {discord.ApplicationCommandOptionTypeAttachment},
+	"OptAttachment":  {discord.ApplicationCommandOptionTypeAttachment},
+	"Mentionable":    {discord.ApplicationCommandOptionTypeMentionable},
+	"OptMentionable": {discord.ApplicationCommandOptionTypeMentionable},
+	"Snowflake": {discord.ApplicationCommandOptionTypeChannel, discord.ApplicationCommandOptionTypeUser,
+		discord.ApplicationCommandOptionTypeRole, discord.ApplicationCommandOptionTypeMentionable, discord.ApplicationCommandOptionTypeAttachment},
+	"OptSnowflake": {discord.ApplicationCommandOptionTypeChannel, discord.ApplicationCommandOptionTypeUser,
+		discord.ApplicationCommandOptionTypeRole, discord.ApplicationCommandOptionTypeMentionable, discord.ApplicationCommandOptionTypeAttachment},
+}
+
+// optionRead is an option a handler reads, found in the package source.
+type optionRead struct {
+	name     string
+	accessor string
+	pos      token.Position
+}
+
+// TestHandlersReadDeclaredOptions fails on any option a command handler reads that its command does not
+// declare with a matching type. Such a read returns the zero value at runtime instead of failing loudly.
+func TestHandlersReadDeclaredOptions(t *testing.T) {
+	methods, fset := parseHandlerMethods(t)
+
+	h := &Handler{}
+	registry := NewCommandRegistry()
+	registry.Register(h.prefixCommands()...)
+	for _, module := range []Module{h.musicModule(), h.starboardModule()} {
+		registry.Register(module.Commands...)
+	}
+
+	for _, cmd := range registry.Commands() {
+		t.Run(cmd.Name, func(t *testing.T) {
+			handler := handlerMethodName(cmd.Handler)
+			method, ok := methods[handler]
+			if !ok {
+				t.Fatalf("handler %s of command %q not found in the package source", handler, cmd.Name)
+			}
+
+			declared := make(map[string][]discord.ApplicationCommandOptionType)
+			collectDeclaredOptions(cmd.Options, declared)
+
+			for _, read := range optionReads(fset, method, methods) {
+				types, ok := declared[read.name]
+				if !ok {
+					t.Errorf("%s: %s reads option %q, which command %q does not declare", read.pos, handler, read.name, cmd.Name)
+					continue
+				}
+				if !slices.ContainsFunc(types, func(optionType discord.ApplicationCommandOptionType) bool {
+					return slices.Contains(optionAccessors[read.accessor], optionType)
+				}) {
+					t.Errorf("%s: %s reads option %q with %s, which does not match its declared type", read.pos, handler, read.name, read.accessor)
+				}
+			}
+		})
+	}
+}
+
+// handlerMethod is a method of *Handler in the package source, with the names its file imports packages as.
+type handlerMethod struct {
+	decl    *ast.FuncDecl
+	imports map[string]bool
+}
+
+// parseHandlerMethods parses the package source and returns the methods of *Handler by name.
+func parseHandlerMethods(t *testing.T) (map[string]handlerMethod, *token.FileSet) {
+	t.Helper()
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, ".", func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("error parsing package: %v", err)
+	}
+
+	methods := make(map[string]handlerMethod)
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			imports := importNames(file)
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if ok && funcDecl.Recv != nil && isHandlerReceiver(funcDecl.Recv) {
+					methods[funcDecl.Name.Name] = handlerMethod{decl: funcDecl, imports: imports}
+				}
+			}
+		}
+	}
+	return methods, fset
+}
+
+// majorVersion matches the version suffix of module paths like github.com/disgoorg/snowflake/v2.
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// importNames returns the names a file refers to its imported packages by.
+func importNames(file *ast.File) map[string]bool {
+	imports := make(map[string]bool)
+	for _, spec := range file.Imports {
+		if spec.Name != nil {
+			imports[spec.Name.Name] = true
+			continue
+		}
+		path, _ := strconv.Unquote(spec.Path.Value)
+		parts := strings.Split(path, "/")
+		name := parts[len(parts)-1]
+		if majorVersion.MatchString(name) && len(parts) > 1 {
+			name = parts[len(parts)-2]
+		}
+		imports[name] = true
+	}
+	return imports
+}
+
+func isHandlerReceiver(recv *ast.FieldList) bool {
+	star, ok := recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	return ok && ident.Name == "Handler"
+}
+
+// handlerMethodName returns the name of the *Handler method a command handler is bound to, like
+// "handleSkip" for h.handleSkip.
+func handlerMethodName(handler CommandHandler) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// collectDeclaredOptions records the types of the options of a command by name, including those of its
+// subcommands. Subcommands share a namespace here, which is enough to catch misspelled or missing options.
+func collectDeclaredOptions(options []discord.ApplicationCommandOption, declared map[string][]discord.ApplicationCommandOptionType) {
+	for _, option := range options {
+		switch option := option.(type) {
+		case discord.ApplicationCommandOptionSubCommandGroup:
+			for _, subCommand := range option.Options {
+				collectDeclaredOptions(subCommand.Options, declared)
+			}
+		case discord.ApplicationCommandOptionSubCommand:
+			collectDeclaredOptions(option.Options, declared)
+		default:
+			declared[option.OptionName()] = append(declared[option.OptionName()], option.Type())
+		}
+	}
+}
+
+// optionReads finds the options a handler reads, following the handler methods it calls on its receiver.
+func optionReads(fset *token.FileSet, method handlerMethod, methods map[string]handlerMethod) []optionRead {
+	var reads []optionRead
+	visited := make(map[*ast.FuncDecl]bool)
+
+	var visit func(method handlerMethod)
+	visit = func(method handlerMethod) {
+		decl := method.decl
+		if visited[decl] || decl.Body == nil || len(decl.Recv.List[0].Names) == 0 {
+			return
+		}
+		visited[decl] = true
+		receiver := decl.Recv.List[0].Names[0].Name
+
+		ast.Inspect(decl.Body, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+
+			// Calls like h.handleAlwaysOnEnable(ctx, event) read options on behalf of the handler
+			if ident, ok := selector.X.(*ast.Ident); ok && ident.Name == receiver {
+				if callee, ok := methods[selector.Sel.Name]; ok && strings.HasPrefix(selector.Sel.Name, "handle") {
+					visit(callee)
+				}
+				return true
+			}
+			// Package functions like slog.String share names with the accessors
+			if ident, ok := selector.X.(*ast.Ident); ok && method.imports[ident.Name] {
+				return true
+			}
+
+			if _, ok := optionAccessors[selector.Sel.Name]; !ok || len(call.Args) != 1 {
+				return true
+			}
+			literal, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				return true
+			}
+			name, err := strconv.Unquote(literal.Value)
+			if err != nil {
+				return true
+			}
+			reads = append(reads, optionRead{name: name, accessor: selector.Sel.Name, pos: fset.Position(literal.Pos())})
+			return true
+		})
+	}
+	visit(method)
+	return reads
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"context"
+	"time"
+)
+
+// eventTimeout bounds the work done for a single event, interaction or timer, including the Lavalink,
+// Discord REST and storage calls it makes, so a hung dependency cannot block a goroutine forever.
+const eventTimeout = 15 * time.Second
+
+// eventContext returns a context for handling one event. It is cancelled when the bot shuts down
+// or after eventTimeout.
+func (h *Handler) eventContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(h.ctx, eventTimeout)
+}
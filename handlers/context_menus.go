@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unccord-bot-go/i18n"
+	"unccord-bot-go/queue"
+	"unccord-bot-go/storage"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// maxRequestedShown caps how many queued tracks of a user are listed by the listening context menu.
+const maxRequestedShown = 5
+
+// musicContextMenus returns the user and message commands of the music module.
+func (h *Handler) musicContextMenus() []Command {
+	return []Command{
+		{
+			Type:      discord.ApplicationCommandTypeMessage,
+			Name:      "Play this link",
+			GuildOnly: true,
+			Music:     true,
+			Handler:   h.handlePlayLink,
+		},
+		{
+			Type:      discord.ApplicationCommandTypeUser,
+			Name:      "What is this user listening to",
+			GuildOnly: true,
+			Handler:   h.handleListening,
+		},
+	}
+}
+
+// starboardContextMenus returns the message commands of the starboard module.
+func (h *Handler) starboardContextMenus() []Command {
+	return []Command{
+		{
+			Type:        discord.ApplicationCommandTypeMessage,
+			Name:        "Force to starboard",
+			Permissions: discord.PermissionManageMessages,
+			GuildOnly:   true,
+			Handler:     h.handleForceStarboard,
+		},
+	}
+}
+
+// handlePlayLink queues the links and attachments of a message for the user who picked it.
+func (h *Handler) handlePlayLink(ctx context.Context, event *CommandEvent) {
+	links := messageLinks(event.MessageCommandInteractionData().TargetMessage())
+	if len(links) == 0 {
+		replyError(event, errNoLinks)
+		return
+	}
+	h.playLinks(ctx, event, links)
+}
+
+// playLinks queues links for the user of a command in their voice channel and tells them how many were queued.
+func (h *Handler) playLinks(ctx context.Context, event *CommandEvent, links []string) {
+	guildID := *event.GuildID()
+	voiceState, ok := h.Client.Caches().VoiceState(guildID, event.User().ID)
+	if !ok || voiceState.ChannelID == nil {
+		replyError(event, errNotInVoice)
+		return
+	}
+
+	response := deferResponse(ctx, event, true)
+	var queued int
+	var lastErr error
+	for _, link := range links {
+		tracks, started, err := h.play(ctx, guildID, *voiceState.ChannelID, link, event.User().ID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		h.announceQueued(ctx, guildID, event.ChannelID(), tracks, started)
+		queued++
+	}
+	if queued == 0 {
+		response.EditError(lastErr)
+		return
+	}
+
+	locale := interactionLocale(event)
+	description := i18n.Text(locale, "music.links_queued_partial", queued, len(links))
+	if queued == len(links) {
+		description = i18n.Count(locale, "music.links_queued", queued)
+	}
+	response.EditEmbed(discord.NewEmbedBuilder().
+		SetTitle(i18n.Text(locale, "music.links_queued.title")).
+		SetDescription(description).
+		SetColor(ColorSuccess).
+		Build())
+}
+
+// handleListening shows the track a user queued that is playing now, or else their upcoming tracks.
+func (h *Handler) handleListening(ctx context.Context, event *CommandEvent) {
+	guildID := *event.GuildID()
+	target := event.UserCommandInteractionData().TargetUser()
+	locale := interactionLocale(event)
+
+	var embed *discord.EmbedBuilder
+	if player := h.Lavalink.ExistingPlayer(guildID); player != nil && player.Track() != nil &&
+		queue.Data(*player.Track()).RequesterID == target.ID {
+		track := *player.Track()
+		embed = trackEmbed(track).
+			SetTitle(i18n.Text(locale, "music.now_playing.title")).
+			SetDescription(i18n.Text(locale, "music.listening", target.Mention(), track.Info.Title, track.Info.Author)).
+			SetColor(ColorSuccess)
+	} else {
+		var list strings.Builder
+		var shown int
+		for i, track := range h.Queues.Get(guildID).Tracks() {
+			if queue.Data(track).RequesterID != target.ID {
+				continue
+			}
+			if shown == maxRequestedShown {
+				list.WriteString("…\n")
+				break
+			}
+			fmt.Fprintf(&list, "%d. %s\n", i+1, i18n.Text(locale, "music.now_playing", track.Info.Title, track.Info.Author))
+			shown++
+		}
+		if shown == 0 {
+			replyError(event, errNothingQueued(target))
+			return
+		}
+		embed = discord.NewEmbedBuilder().
+			SetTitle(i18n.Text(locale, "music.requested.title")).
+			SetDescription(i18n.Text(locale, "music.requested", target.Mention(), list.String())).
+			SetColor(ColorInfo)
+	}
+
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(embed.Build()).
+		SetEphemeral(true).
+		Build()); err != nil {
+		slog.Error("Failed to send listening tracks", slog.Any("err", err))
+	}
+}
+
+// handleForceStarboard posts a message to the starboard regardless of its stars.
+func (h *Handler) handleForceStarboard(ctx context.Context, event *CommandEvent) {
+	message := event.MessageCommandInteractionData().TargetMessage()
+	response := deferResponse(ctx, event, true)
+
+	_, err := h.Store.StarboardMessageID(ctx, message.ID)
+	if err == nil {
+		response.EditError(errAlreadyOnStarboard)
+		return
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		response.EditError(fmt.Errorf("error checking existing starboard message: %w", err))
+		return
+	}
+
+	starred := storage.StarredMessage{MessageID: message.ID, ChannelID: message.ChannelID, AuthorID: message.Author.ID, Content: message.Content}
+	if err = h.Store.RecordStarred(ctx, starred); err != nil {
+		response.EditError(fmt.Errorf("error recording message %s: %w", message.ID, err))
+		return
+	}
+	starCount, err := h.Store.StarCount(ctx, message.ID)
+	if err != nil {
+		response.EditError(fmt.Errorf("error fetching star count of message %s: %w", message.ID, err))
+		return
+	}
+
+	if err = h.PostToStarboard(ctx, event.Client(), *event.GuildID(), &message, starCount); err != nil {
+		response.EditError(err)
+		return
+	}
+	locale := interactionLocale(event)
+	response.EditEmbed(discord.NewEmbedBuilder().
+		SetTitle(i18n.Text(locale, "starboard.forced.title")).
+		SetDescription(i18n.Text(locale, "starboard.forced")).
+		SetColor(ColorSuccess).
+		Build())
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unccord-bot-go/i18n"
+	"unccord-bot-go/queue"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Control panel buttons use custom IDs of the form "music:<action>:<guildID>" so that
+// presses can be validated against the guild's current player and panel.
+const panelCustomIDPrefix = "music"
+
+const (
+	panelActionRewind    = "rewind"
+	panelActionPlayPause = "playpause"
+	panelActionSkip      = "skip"
+	panelActionStop      = "stop"
+	panelActionLoop      = "loop"
+	panelActionShuffle   = "shuffle"
+	panelActionQueue     = "queue"
+	panelActionLike      = "like"
+)
+
+// maxQueueShown caps how many upcoming tracks are listed in the queue embed.
+const maxQueueShown = 10
+
+// rewindSeconds is how far the rewind button jumps back.
+const rewindSeconds = 10
+
+func panelCustomID(action string, guildID snowflake.ID) string {
+	return fmt.Sprintf("%s:%s:%s", panelCustomIDPrefix, action, guildID)
+}
+
+// parsePanelCustomID splits a control panel custom ID into its action and guild ID.
+func parsePanelCustomID(customID string) (string, snowflake.ID, bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 3 || parts[0] != panelCustomIDPrefix {
+		return "", 0, false
+	}
+
+	guildID, err := snowflake.Parse(parts[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[1], guildID, true
+}
+
+func (h *Handler) setCurrentPanel(guildID, messageID snowflake.ID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.panels[guildID] = messageID
+}
+
+func (h *Handler) isCurrentPanel(guildID, messageID snowflake.ID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.panels[guildID] == messageID
+}
+
+func (h *Handler) OnComponentInteraction(ctx context.Context, event *events.ComponentInteractionCreate) {
+	if event.GuildID() == nil {
+		return
+	}
+
+	action, guildID, ok := parsePanelCustomID(event.Data.CustomID())
+	if !ok || guildID != *event.GuildID() || !h.isCurrentPanel(guildID, event.Message.ID) {
+		replyError(event, errPanelInactive)
+		return
+	}
+
+	if !bypassRateLimit(event.Member()) {
+		if ok, wait := h.limiter.allow(rateLimitPanel, event.User().ID, &guildID); !ok {
+			replyError(event, errCooldown(wait))
+			return
+		}
+	}
+
+	if !h.musicAvailable() {
+		replyError(event, errMusicUnavailable)
+		return
+	}
+
+	player := h.Lavalink.ExistingPlayer(guildID)
+	if player == nil {
+		replyError(event, errNoPlayer)
+		return
+	}
+
+	switch action {
+	case panelActionRewind:
+		h.handleRewind(ctx, event, player)
+	case panelActionPlayPause:
+		h.handlePlayPause(ctx, event, player)
+	case panelActionSkip:
+		h.handleSkipButton(ctx, event)
+	case panelActionStop:
+		h.handleStopButton(ctx, event, player)
+	case panelActionLoop:
+		h.handleLoopButton(ctx, event)
+	case panelActionShuffle:
+		h.handleShuffleButton(ctx, event)
+	case panelActionQueue:
+		h.handleQueueButton(ctx, event)
+	case panelActionLike:
+		h.handleLikeButton(ctx, event, player)
+	}
+}
+
+func (h *Handler) createControlPanel(ctx context.Context, channelID, guildID snowflake.ID) {
+	player := h.Lavalink.ExistingPlayer(guildID)
+	if player == nil {
+		slog.Error("No active player found", "guildID", guildID)
+		return
+	}
+
+	currentTrack := player.Track()
+	if currentTrack == nil {
+		slog.Error("No current track found", "guildID", guildID)
+		return
+	}
+
+	// The panel is seen by everyone in the channel, so it is in the guild's locale
+	locale := h.guildLocale(guildID)
+	message, err := h.Client.Rest().CreateMessage(channelID, discord.NewMessageCreateBuilder().
+		SetContent("").
+		SetEmbeds(h.controlPanelEmbed(locale, guildID, *currentTrack).Build()).
+		AddActionRow(
+			discord.NewSecondaryButton(i18n.Text(locale, "panel.rewind"), panelCustomID(panelActionRewind, guildID)),
+			discord.NewPrimaryButton(i18n.Text(locale, "panel.play_pause"), panelCustomID(panelActionPlayPause, guildID)),
+			discord.NewSecondaryButton(i18n.Text(locale, "panel.skip"), panelCustomID(panelActionSkip, guildID)),
+			discord.NewDangerButton(i18n.Text(locale, "panel.stop"), panelCustomID(panelActionStop, guildID)),
+		).
+		AddActionRow(
+			discord.NewSecondaryButton(i18n.Text(locale, "panel.loop"), panelCustomID(panelActionLoop, guildID)),
+			discord.NewSecondaryButton(i18n.Text(locale, "panel.shuffle"), panelCustomID(panelActionShuffle, guildID)),
+			discord.NewSecondaryButton(i18n.Text(locale, "panel.queue"), panelCustomID(panelActionQueue, guildID)),
+			discord.NewSuccessButton(i18n.Text(locale, "panel.like"), panelCustomID(panelActionLike, guildID)),
+		).
+		Build(),
+		rest.WithCtx(ctx),
+	)
+
+	if err != nil {
+		slog.Error("Failed to create control panel", slog.Any("err", err))
+	} else {
+		h.setCurrentPanel(guildID, message.ID)
+		slog.Info("Control panel created successfully", "guildID", guildID)
+	}
+}
+
+// controlPanelEmbed shows the current track of a guild together with the state of its queue.
+func (h *Handler) controlPanelEmbed(locale discord.Locale, guildID snowflake.ID, track lavalink.Track) *discord.EmbedBuilder {
+	queue := h.Queues.Get(guildID)
+	return trackEmbed(track).
+		SetTitle(i18n.Text(locale, "music.now_playing.title")).
+		SetDescription(i18n.Text(locale, "panel.now_playing", trackLabel(locale, track), track.Info.Author, queue.Len(), loopModeName(locale, queue.Loop()))).
+		SetColor(ColorInfo)
+}
+
+func (h *Handler) handlePlayPause(ctx context.Context, event *events.ComponentInteractionCreate, player disgolink.Player) {
+	status := "music.paused"
+	if player.Paused() {
+		status = "music.resumed"
+	}
+	if err := player.Update(ctx, lavalink.WithPaused(!player.Paused())); err != nil {
+		replyError(event, fmt.Errorf("error toggling pause: %w", err))
+		return
+	}
+
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().SetContent(i18n.Text(interactionLocale(event), status)).SetEphemeral(true).Build()); err != nil {
+		slog.Error("Failed to send playback status", slog.Any("err", err))
+	}
+}
+
+func (h *Handler) handleRewind(ctx context.Context, event *events.ComponentInteractionCreate, player disgolink.Player) {
+	currentPosition := player.Position()
+	newPosition := currentPosition - lavalink.Duration(rewindSeconds*lavalink.Second)
+	if newPosition < 0 {
+		newPosition = 0
+	}
+
+	if err := player.Update(ctx, lavalink.WithPosition(newPosition)); err != nil {
+		replyError(event, fmt.Errorf("error rewinding: %w", err))
+		return
+	}
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().SetContent(i18n.Text(interactionLocale(event), "music.rewound", rewindSeconds)).SetEphemeral(true).Build()); err != nil {
+		slog.Error("Failed to send rewind confirmation", slog.Any("err", err))
+	}
+}
+
+func (h *Handler) handleSkipButton(ctx context.Context, event *events.ComponentInteractionCreate) {
+	response := deferResponse(ctx, event, true)
+	// Skip like /skip does, so a looping queue keeps the skipped track
+	embed, err := h.skipTracks(ctx, interactionLocale(event), *event.GuildID(), 1)
+	if err != nil {
+		response.EditError(err)
+		return
+	}
+	response.EditEmbed(embed.Build())
+}
+
+func (h *Handler) handleStopButton(ctx context.Context, event *events.ComponentInteractionCreate, player disgolink.Player) {
+	h.Queues.Get(*event.GuildID()).Clear()
+
+	response := deferResponse(ctx, event, true)
+	if err := player.Update(ctx, lavalink.WithNullTrack()); err != nil {
+		response.EditError(fmt.Errorf("error stopping player: %w", err))
+		return
+	}
+
+	h.startIdleTimer(*event.GuildID())
+	response.Edit(discord.NewMessageUpdateBuilder().SetContent(i18n.Text(interactionLocale(event), "music.stopped")).Build())
+}
+
+func (h *Handler) handleLoopButton(ctx context.Context, event *events.ComponentInteractionCreate) {
+	loop := h.Queues.Get(*event.GuildID()).CycleLoop()
+
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().SetContent(i18n.Text(interactionLocale(event), "music.loop_set", loopModeName(interactionLocale(event), loop))).SetEphemeral(true).Build()); err != nil {
+		slog.Error("Failed to send loop mode", slog.Any("err", err))
+	}
+}
+
+func (h *Handler) handleShuffleButton(ctx context.Context, event *events.ComponentInteractionCreate) {
+	if !h.Queues.Get(*event.GuildID()).Shuffle() {
+		replyError(event, errNotEnoughToShuffle)
+		return
+	}
+
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().SetContent(i18n.Text(interactionLocale(event), "music.shuffled")).SetEphemeral(true).Build()); err != nil {
+		slog.Error("Failed to send shuffle confirmation", slog.Any("err", err))
+	}
+}
+
+func (h *Handler) handleQueueButton(ctx context.Context, event *events.ComponentInteractionCreate) {
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(h.queueEmbed(interactionLocale(event), *event.GuildID()).Build()).
+		SetEphemeral(true).
+		Build()); err != nil {
+		slog.Error("Failed to send queue", slog.Any("err", err))
+	}
+}
+
+func (h *Handler) handleLikeButton(ctx context.Context, event *events.ComponentInteractionCreate, player disgolink.Player) {
+	currentTrack := player.Track()
+	if currentTrack == nil {
+		replyError(event, errNothingPlaying)
+		return
+	}
+
+	response := deferResponse(ctx, event, true)
+	added, err := h.Store.AddFavourite(ctx, event.User().ID, *currentTrack)
+	if err != nil {
+		response.EditError(fmt.Errorf("error saving favourite of user %s: %w", event.User().ID, err))
+		return
+	}
+
+	id := "favourites.saved"
+	if !added {
+		id = "favourites.already_saved"
+	}
+	response.Edit(discord.NewMessageUpdateBuilder().SetContent(i18n.Text(interactionLocale(event), id, currentTrack.Info.Title)).Build())
+}
+
+// queueEmbed lists the current track and the upcoming tracks of a guild.
+func (h *Handler) queueEmbed(locale discord.Locale, guildID snowflake.ID) *discord.EmbedBuilder {
+	queue := h.Queues.Get(guildID)
+	tracks := queue.Tracks()
+
+	var description strings.Builder
+	if player := h.Lavalink.ExistingPlayer(guildID); player != nil && player.Track() != nil {
+		description.WriteString(i18n.Text(locale, "music.queue.now_playing", trackLabel(locale, *player.Track()), player.Track().Info.Author) + "\n\n")
+	}
+
+	if len(tracks) == 0 {
+		description.WriteString(i18n.Text(locale, "music.queue.empty"))
+	}
+	for i, track := range tracks {
+		if i == maxQueueShown {
+			description.WriteString(i18n.Text(locale, "music.queue.more", len(tracks)-maxQueueShown))
+			break
+		}
+		description.WriteString(i18n.Text(locale, "music.track_entry", i+1, track.Info.Title, track.Info.Author) + "\n")
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle(i18n.Text(locale, "music.queue.title")).
+		SetDescription(description.String()).
+		SetFooterText(i18n.Text(locale, "music.queue.footer", loopModeName(locale, queue.Loop()), stateName(locale, queue.Autoplay()))).
+		SetColor(ColorInfo)
+}
+
+// loopModeName returns the name of a loop mode in locale.
+func loopModeName(locale discord.Locale, mode queue.LoopMode) string {
+	return i18n.Text(locale, "music.loop."+mode.String())
+}
+
+// stateName returns "on" or "off" in locale.
+func stateName(locale discord.Locale, enabled bool) string {
+	if enabled {
+		return i18n.Text(locale, "music.state.on")
+	}
+	return i18n.Text(locale, "music.state.off")
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// responseTimeout bounds sending a deferred response. It is separate from the event context so a
+// response explaining a timeout can still be sent after the event's deadline has passed.
+const responseTimeout = 5 * time.Second
+
+// deferrable is an interaction whose response can be deferred, like a slash command or a button press.
+type deferrable interface {
+	localized
+	Client() bot.Client
+	ApplicationID() snowflake.ID
+	Token() string
+	DeferCreateMessage(ephemeral bool, opts ...rest.RequestOpt) error
+}
+
+// deferredResponse is the response to an interaction that was acknowledged before its work finished.
+// Discord fails interactions that are not answered within three seconds, which Lavalink, database and
+// REST calls can exceed. The interaction token stays valid for 15 minutes to edit the response and
+// send follow-ups. Text commands have no token; their responses are replies to the message.
+type deferredResponse struct {
+	ctx   context.Context
+	event deferrable
+	reply *messageReply
+}
+
+// deferResponse acknowledges an interaction, showing "thinking" until the response is edited.
+func deferResponse(ctx context.Context, event deferrable, ephemeral bool) *deferredResponse {
+	if err := event.DeferCreateMessage(ephemeral, rest.WithCtx(ctx)); err != nil {
+		slog.Error("Failed to defer interaction response", slog.Any("err", err))
+	}
+	response := &deferredResponse{ctx: ctx, event: event}
+	if event, ok := event.(*CommandEvent); ok {
+		response.reply = event.reply
+	}
+	return response
+}
+
+// requestContext returns a context for a request about the interaction that outlives the event context.
+func (r *deferredResponse) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(r.ctx), responseTimeout)
+}
+
+// Edit replaces the "thinking" state or the previous content of the response.
+func (r *deferredResponse) Edit(message discord.MessageUpdate) {
+	ctx, cancel := r.requestContext()
+	defer cancel()
+	var err error
+	if r.reply != nil {
+		err = r.reply.edit(message, rest.WithCtx(ctx))
+	} else {
+		_, err = r.event.Client().Rest().UpdateInteractionResponse(r.event.ApplicationID(), r.event.Token(), message, rest.WithCtx(ctx))
+	}
+	if err != nil {
+		slog.Error("Failed to edit interaction response", slog.Any("err", err))
+	}
+}
+
+// EditEmbed replaces the response with a single embed.
+func (r *deferredResponse) EditEmbed(embed discord.Embed) {
+	r.Edit(discord.NewMessageUpdateBuilder().
+		SetContent("").
+		SetEmbeds(embed).
+		Build())
+}
+
+// EditError replaces the response with err, rendered by errorEmbed in the interaction's locale.
+func (r *deferredResponse) EditError(err error) {
+	r.EditEmbed(errorEmbed(interactionLocale(r.event), err))
+}
+
+// FollowUp sends another message about the interaction, for example to report progress.
+func (r *deferredResponse) FollowUp(message discord.MessageCreate) {
+	ctx, cancel := r.requestContext()
+	defer cancel()
+	var err error
+	if r.reply != nil {
+		err = r.reply.CreateMessage(message, rest.WithCtx(ctx))
+	} else {
+		_, err = r.event.Client().Rest().CreateFollowupMessage(r.event.ApplicationID(), r.event.Token(), message, rest.WithCtx(ctx))
+	}
+	if err != nil {
+		slog.Error("Failed to send follow-up message", slog.Any("err", err))
+	}
+}
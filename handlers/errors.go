@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"unccord-bot-go/i18n"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+)
+
+// UserError is an error the user can do something about, like using a music command outside voice.
+// Its message is shown as is, in the user's language. All other errors are internal: users only see a
+// generic message with a correlation ID that matches the logged error.
+type UserError struct {
+	ID   string // Catalog ID of the message; the title is the entry with ".title" appended
+	Args []any  // Arguments of the message
+	Err  error  // Underlying cause, only logged
+}
+
+func (e *UserError) Error() string {
+	message := i18n.Text(i18n.Fallback, e.ID, e.Args...)
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", message, e.Err)
+	}
+	return message
+}
+
+func (e *UserError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	errNotInVoice         = &UserError{ID: "error.not_in_voice"}
+	errNoPlayer           = &UserError{ID: "error.no_player"}
+	errNothingPlaying     = &UserError{ID: "error.nothing_playing"}
+	errQueueEmpty         = &UserError{ID: "error.queue_empty"}
+	errNotEnoughToShuffle = &UserError{ID: "error.not_enough_to_shuffle"}
+	errAlwaysOnLeave      = &UserError{ID: "error.always_on_leave"}
+	errOutdatedCommand    = &UserError{ID: "error.outdated_command"}
+	errPanelInactive      = &UserError{ID: "error.panel_inactive"}
+	errGuildOnly          = &UserError{ID: "error.guild_only"}
+	errMusicUnavailable   = &UserError{ID: "error.music_unavailable"}
+	errTimeout            = &UserError{ID: "error.timeout"}
+	errNoLinks            = &UserError{ID: "error.no_links"}
+	errAlreadyOnStarboard = &UserError{ID: "error.already_on_starboard"}
+	errNoFavourites       = &UserError{ID: "error.no_favourites"}
+	errNotALink           = &UserError{ID: "error.not_a_link"}
+	errInvalidPrefix      = &UserError{ID: "error.invalid_prefix", Args: []any{maxPrefixLength}}
+	errShuttingDown       = &UserError{ID: "error.shutting_down"}
+)
+
+// errPermissionDenied is returned when a member lacks the permissions a command requires.
+func errPermissionDenied(permissions discord.Permissions) *UserError {
+	return &UserError{ID: "error.permission_denied", Args: []any{permissions}}
+}
+
+// errCooldown is returned when a command is used more often than its rate limit allows.
+func errCooldown(remaining time.Duration) *UserError {
+	return &UserError{ID: "error.cooldown", Args: []any{max(remaining.Round(time.Second), time.Second)}}
+}
+
+// errLoadFailed is returned when Lavalink cannot load what the user asked for. err is nil when nothing matched.
+func errLoadFailed(query string, err error) *UserError {
+	if err != nil {
+		return &UserError{ID: "error.load_failed", Args: []any{query}, Err: err}
+	}
+	return &UserError{ID: "error.no_matches", Args: []any{query}}
+}
+
+// errInvalidArguments is returned when the arguments of a text command do not fit its options. usage
+// shows how to use the command.
+func errInvalidArguments(usage string, err error) *UserError {
+	return &UserError{ID: "error.invalid_arguments", Args: []any{usage}, Err: err}
+}
+
+// errUnknownCommand is returned when help is asked for a command that does not exist. help is the text
+// command that lists the commands.
+func errUnknownCommand(help string) *UserError {
+	return &UserError{ID: "error.unknown_command", Args: []any{help}}
+}
+
+// errNothingQueued is returned when a user has no track playing or queued.
+func errNothingQueued(user discord.User) *UserError {
+	return &UserError{ID: "error.nothing_queued", Args: []any{user.Mention()}}
+}
+
+// asUserError returns the user-facing form of err, if it has one.
+func asUserError(err error) (*UserError, bool) {
+	var userErr *UserError
+	switch {
+	case errors.As(err, &userErr):
+		return userErr, true
+	case errors.Is(err, errNoNode):
+		return errMusicUnavailable, true
+	case errors.Is(err, context.DeadlineExceeded):
+		return errTimeout, true
+	case errors.Is(err, context.Canceled):
+		return errShuttingDown, true
+	}
+	return nil, false
+}
+
+// errorEmbed renders err for users in locale. Internal errors are logged with a new correlation ID, which
+// the embed shows so that reports can be matched to the logs.
+func errorEmbed(locale discord.Locale, err error) discord.Embed {
+	if userErr, ok := asUserError(err); ok {
+		return discord.NewEmbedBuilder().
+			SetTitle(i18n.Text(locale, userErr.ID+".title")).
+			SetDescription(i18n.Text(locale, userErr.ID, userErr.Args...)).
+			SetColor(ColorWarning).
+			Build()
+	}
+
+	errorID := newErrorID()
+	slog.Error("Internal error", slog.Any("err", err), "errorID", errorID)
+	return discord.NewEmbedBuilder().
+		SetTitle(i18n.Text(locale, "error.internal.title")).
+		SetDescription(i18n.Text(locale, "error.internal")).
+		SetColor(ColorError).
+		SetFooterText(i18n.Text(locale, "error.id", errorID)).
+		Build()
+}
+
+// newErrorID returns a short random ID to correlate an error shown to a user with the logs.
+func newErrorID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// messageResponder is an interaction that can be answered with a message.
+type messageResponder interface {
+	localized
+	CreateMessage(messageCreate discord.MessageCreate, opts ...rest.RequestOpt) error
+}
+
+// replyError answers an interaction with err as an ephemeral embed.
+func replyError(event messageResponder, err error) {
+	sendErr := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(errorEmbed(interactionLocale(event), err)).
+		SetEphemeral(true).
+		Build())
+	if sendErr != nil {
+		slog.Error("Failed to send error message", slog.Any("err", sendErr))
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unccord-bot-go/i18n"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// maxFavouritesShown caps how many favourites are listed by /favourites.
+const maxFavouritesShown = 20
+
+func (h *Handler) handleFavourites(ctx context.Context, event *CommandEvent) {
+	response := deferResponse(ctx, event, true)
+	favourites, err := h.Store.Favourites(ctx, event.User().ID, maxFavouritesShown)
+	if err != nil {
+		response.EditError(fmt.Errorf("error fetching favourites of user %s: %w", event.User().ID, err))
+		return
+	}
+
+	if len(favourites) == 0 {
+		response.EditError(errNoFavourites)
+		return
+	}
+
+	locale := interactionLocale(event)
+	var list strings.Builder
+	for i, favourite := range favourites {
+		title := favourite.Title
+		if favourite.URI != "" {
+			title = fmt.Sprintf("[%s](%s)", favourite.Title, favourite.URI)
+		}
+		list.WriteString(i18n.Text(locale, "music.track_entry", i+1, title, favourite.Author) + "\n")
+	}
+
+	response.EditEmbed(discord.NewEmbedBuilder().
+		SetTitle(i18n.Text(locale, "favourites.title")).
+		SetDescription(list.String()).
+		SetColor(ColorInfo).
+		Build())
+}
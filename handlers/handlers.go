@@ -1,38 +1,112 @@
 package handlers
 
 import (
+	"context"
 	"sync"
+	"time"
 	"unccord-bot-go/queue"
+	"unccord-bot-go/storage"
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/snowflake/v2"
 )
 
 type Handler struct {
+	ctx      context.Context // Root context, cancelled when the bot shuts down
 	Client   bot.Client
 	Lavalink disgolink.Client
 	Queues   *queue.QueueManager
+	Store    storage.Store
 	mu       sync.Mutex
+	modules  []Module
+	commands *CommandRegistry
+	limiter  *rateLimiter
+	panels   map[snowflake.ID]snowflake.ID // guild ID -> message ID of the current control panel
+	prefixes map[snowflake.ID]string       // guild ID -> prefix of text commands, empty if they are off
+
+	idleTimers    map[snowflake.ID]*time.Timer
+	emptyTimers   map[snowflake.ID]*time.Timer
+	pausedOnEmpty map[snowflake.ID]bool
+	alwaysOn      map[snowflake.ID]storage.AlwaysOnSettings
+	voiceServers  map[snowflake.ID]voiceServer
+	pendingGuilds map[snowflake.ID]struct{}     // Guilds whose music setup waits for a Lavalink node
+	pendingRejoin map[snowflake.ID]struct{}     // Guilds whose 24/7 channel is joined once a Lavalink node connects
+	nodeCancels   map[string]context.CancelFunc // Node name -> stops its connection attempts
 }
 
-func NewHandler() *Handler {
-	return &Handler{
-		Queues: queue.NewQueueManager(),
+// NewHandler creates the handler with the modules enabled in the active configuration,
+// keeping its data in store. Work started by the handler stops when ctx is cancelled.
+func NewHandler(ctx context.Context, store storage.Store) *Handler {
+	h := &Handler{
+		ctx:      ctx,
+		Store:    store,
+		Queues:   queue.NewQueueManager(),
+		panels:   make(map[snowflake.ID]snowflake.ID),
+		prefixes: make(map[snowflake.ID]string),
+		limiter:  newRateLimiter(),
+
+		idleTimers:    make(map[snowflake.ID]*time.Timer),
+		emptyTimers:   make(map[snowflake.ID]*time.Timer),
+		pausedOnEmpty: make(map[snowflake.ID]bool),
+		alwaysOn:      make(map[snowflake.ID]storage.AlwaysOnSettings),
+		voiceServers:  make(map[snowflake.ID]voiceServer),
+		pendingGuilds: make(map[snowflake.ID]struct{}),
+		pendingRejoin: make(map[snowflake.ID]struct{}),
+		nodeCancels:   make(map[string]context.CancelFunc),
 	}
+	h.modules = h.loadModules()
+	h.commands = h.loadCommands()
+	return h
 }
 
+// OnEvent dispatches gateway events to the enabled modules and slash commands to the command registry,
+// bounding their work with an event context. Messages starting with the guild's prefix are text commands
+// and go to the command registry as well. A panic is recovered per module, so it neither crashes the
+// bot nor keeps the other modules from seeing the event.
 func (h *Handler) OnEvent(event bot.Event) {
-	switch e := event.(type) {
-	case *events.MessageCreate:
-		h.OnMessageCreate(e)
-	case *events.GuildVoiceStateUpdate:
-		h.OnVoiceStateUpdate(e)
-	case *events.VoiceServerUpdate:
-		h.OnVoiceServerUpdate(e)
-	case *events.ComponentInteractionCreate:
-		h.OnComponentInteraction(e)
-	case *events.ApplicationCommandInteractionCreate:
-		h.HandleSlashCommand(e)
+	ctx, cancel := h.eventContext()
+	defer cancel()
+	if e, ok := event.(*events.ApplicationCommandInteractionCreate); ok {
+		defer func() {
+			if r := recover(); r != nil {
+				recoverPanic(event, r)
+			}
+		}()
+		h.HandleSlashCommand(ctx, e)
+		return
+	}
+	if e, ok := event.(*events.MessageCreate); ok && h.handlePrefixCommand(ctx, e) {
+		return
 	}
+	for _, module := range h.modules {
+		h.dispatch(ctx, module, event)
+	}
+}
+
+func (h *Handler) dispatch(ctx context.Context, module Module, event bot.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			recoverPanic(event, r, "module", module.Name)
+		}
+	}()
+	module.OnEvent(ctx, event)
+}
+
+// OnGuildReady sets up music for the guild, or postpones it until a Lavalink node is connected.
+func (h *Handler) OnGuildReady(ctx context.Context, event *events.GuildReady) {
+	if !h.musicAvailable() {
+		h.mu.Lock()
+		h.pendingGuilds[event.Guild.ID] = struct{}{}
+		h.mu.Unlock()
+		return
+	}
+	h.setupGuildMusic(ctx, event.Guild.ID)
+}
+
+// setupGuildMusic restores the guild's saved player and applies its 24/7 settings.
+func (h *Handler) setupGuildMusic(ctx context.Context, guildID snowflake.ID) {
+	h.restorePlayer(ctx, guildID)
+	h.loadAlwaysOn(ctx, guildID)
 }
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"unccord-bot-go/config"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// scheduleTimer (re)starts the timer of a guild in timers, running f as the named task when it fires. A
+// non-positive duration disables the timer.
+func (h *Handler) scheduleTimer(timers map[snowflake.ID]*time.Timer, guildID snowflake.ID, d time.Duration, task string, f func(ctx context.Context)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if timer, ok := timers[guildID]; ok {
+		timer.Stop()
+		delete(timers, guildID)
+	}
+	if d <= 0 {
+		return
+	}
+	timers[guildID] = time.AfterFunc(d, func() {
+		h.runTask(task, f, "guildID", guildID)
+	})
+}
+
+// stopTimer cancels the timer of a guild in timers, if any.
+func (h *Handler) stopTimer(timers map[snowflake.ID]*time.Timer, guildID snowflake.ID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if timer, ok := timers[guildID]; ok {
+		timer.Stop()
+		delete(timers, guildID)
+	}
+}
+
+// startIdleTimer disconnects from voice once the queue has stayed empty for the configured idle timeout.
+// Guilds in 24/7 mode are exempt.
+func (h *Handler) startIdleTimer(guildID snowflake.ID) {
+	if h.isAlwaysOn(guildID) {
+		return
+	}
+	h.scheduleTimer(h.idleTimers, guildID, config.Get().Music.IdleTimeout, "idle timer", func(ctx context.Context) {
+		slog.Info("Idle timeout reached, leaving voice channel", "guildID", guildID)
+		if err := h.disconnect(ctx, guildID); err != nil {
+			slog.Error("Failed to disconnect idle player", slog.Any("err", err), "guildID", guildID)
+		}
+	})
+}
+
+func (h *Handler) stopIdleTimer(guildID snowflake.ID) {
+	h.stopTimer(h.idleTimers, guildID)
+}
+
+// checkVoiceChannelEmpty pauses playback and starts the leave timer when nobody but the bot
+// is left in its voice channel, and undoes both once someone joins again.
+func (h *Handler) checkVoiceChannelEmpty(ctx context.Context, guildID snowflake.ID) {
+	selfState, ok := h.Client.Caches().VoiceState(guildID, h.Client.ApplicationID())
+	if !ok || selfState.ChannelID == nil {
+		return
+	}
+
+	if h.listenerCount(guildID, *selfState.ChannelID) > 0 {
+		h.stopTimer(h.emptyTimers, guildID)
+		h.resumeIfPausedOnEmpty(ctx, guildID)
+		return
+	}
+
+	h.mu.Lock()
+	_, pending := h.emptyTimers[guildID]
+	h.mu.Unlock()
+	if pending {
+		return
+	}
+
+	slog.Info("Voice channel is empty", "guildID", guildID, "channelID", *selfState.ChannelID)
+	h.pauseOnEmpty(ctx, guildID)
+
+	timeout := config.Get().Music.EmptyChannelTimeout
+	if h.isAlwaysOn(guildID) {
+		// 24/7 guilds stay in their channel even when nobody is listening
+		timeout = 0
+	}
+	h.scheduleTimer(h.emptyTimers, guildID, timeout, "empty channel timer", func(ctx context.Context) {
+		slog.Info("Voice channel stayed empty, leaving", "guildID", guildID)
+		if err := h.disconnect(ctx, guildID); err != nil {
+			slog.Error("Failed to leave empty voice channel", slog.Any("err", err), "guildID", guildID)
+		}
+	})
+}
+
+// listenerCount returns how many users other than the bot are connected to a voice channel.
+func (h *Handler) listenerCount(guildID, channelID snowflake.ID) int {
+	count := 0
+	h.Client.Caches().VoiceStatesForEach(guildID, func(state discord.VoiceState) {
+		if state.UserID != h.Client.ApplicationID() && state.ChannelID != nil && *state.ChannelID == channelID {
+			count++
+		}
+	})
+	return count
+}
+
+func (h *Handler) pauseOnEmpty(ctx context.Context, guildID snowflake.ID) {
+	if !config.Get().Music.PauseOnEmpty {
+		return
+	}
+
+	player := h.Lavalink.ExistingPlayer(guildID)
+	if player == nil || player.Track() == nil || player.Paused() {
+		return
+	}
+
+	if err := player.Update(ctx, lavalink.WithPaused(true)); err != nil {
+		slog.Error("Failed to pause player in empty channel", slog.Any("err", err), "guildID", guildID)
+		return
+	}
+
+	h.mu.Lock()
+	h.pausedOnEmpty[guildID] = true
+	h.mu.Unlock()
+}
+
+func (h *Handler) resumeIfPausedOnEmpty(ctx context.Context, guildID snowflake.ID) {
+	h.mu.Lock()
+	paused := h.pausedOnEmpty[guildID]
+	delete(h.pausedOnEmpty, guildID)
+	h.mu.Unlock()
+	if !paused {
+		return
+	}
+
+	player := h.Lavalink.ExistingPlayer(guildID)
+	if player == nil {
+		return
+	}
+
+	if err := player.Update(ctx, lavalink.WithPaused(false)); err != nil {
+		slog.Error("Failed to resume player", slog.Any("err", err), "guildID", guildID)
+		return
+	}
+	slog.Info("Listener rejoined, resumed playback", "guildID", guildID)
+}
+
+// clearVoiceTimers drops all pending timers and pause state of a guild, e.g. after the bot left voice.
+func (h *Handler) clearVoiceTimers(guildID snowflake.ID) {
+	h.stopTimer(h.idleTimers, guildID)
+	h.stopTimer(h.emptyTimers, guildID)
+
+	h.mu.Lock()
+	delete(h.pausedOnEmpty, guildID)
+	h.mu.Unlock()
+}
+
+// disconnect destroys the player of a guild, clears its queue and leaves the voice channel.
+func (h *Handler) disconnect(ctx context.Context, guildID snowflake.ID) error {
+	h.clearVoiceTimers(guildID)
+	h.Queues.Get(guildID).Clear()
+	if err := h.Store.DeleteSavedPlayer(ctx, guildID); err != nil {
+		slog.Error("Failed to delete saved player state", slog.Any("err", err), "guildID", guildID)
+	}
+
+	if player := h.Lavalink.ExistingPlayer(guildID); player != nil {
+		if err := player.Destroy(ctx); err != nil {
+			return fmt.Errorf("failed to destroy player: %w", err)
+		}
+	}
+
+	if err := h.Client.UpdateVoiceState(ctx, guildID, nil, false, false); err != nil {
+		return fmt.Errorf("failed to leave voice channel: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"unccord-bot-go/config"
+
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+)
+
+// ConnectNode adds a Lavalink node, resuming its previous session if one was stored, and
+// configures the new session to survive the bot disconnecting for up to the resume timeout.
+func (h *Handler) ConnectNode(ctx context.Context, nodeConfig disgolink.NodeConfig) (disgolink.Node, error) {
+	resumeTimeout := config.Get().Lavalink.ResumeTimeout
+	if resumeTimeout > 0 {
+		sessionID, err := h.Store.LavalinkSessionID(ctx, nodeConfig.Name)
+		if err != nil {
+			slog.Error("Failed to load Lavalink session ID", slog.Any("err", err), "node", nodeConfig.Name)
+		}
+		nodeConfig.SessionID = sessionID
+	}
+
+	node, err := h.Lavalink.AddNode(ctx, nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if resumeTimeout <= 0 {
+		return node, nil
+	}
+
+	resuming := true
+	timeout := int(resumeTimeout.Seconds())
+	if err = node.Update(ctx, lavalink.SessionUpdate{Resuming: &resuming, Timeout: &timeout}); err != nil {
+		return node, fmt.Errorf("failed to enable session resuming: %w", err)
+	}
+
+	if err = h.Store.SetLavalinkSessionID(ctx, nodeConfig.Name, node.SessionID()); err != nil {
+		slog.Error("Failed to store Lavalink session ID", slog.Any("err", err), "node", nodeConfig.Name)
+	}
+
+	slog.Info("Lavalink session resuming enabled", "node", nodeConfig.Name, "session_id", node.SessionID(),
+		"resumed", nodeConfig.SessionID != "" && nodeConfig.SessionID == node.SessionID(), "timeout", resumeTimeout)
+	return node, nil
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"strings"
+	"unccord-bot-go/i18n"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// localized is an interaction, which tells the locale of the user and of the guild it came from.
+type localized interface {
+	Locale() discord.Locale
+	GuildLocale() *discord.Locale
+}
+
+// interactionLocale returns the locale to answer an interaction in: the user's if the catalog is
+// translated into it, else the guild's, else the fallback.
+func interactionLocale(event localized) discord.Locale {
+	if i18n.Supported(event.Locale()) {
+		return event.Locale()
+	}
+	if locale := event.GuildLocale(); locale != nil && i18n.Supported(*locale) {
+		return *locale
+	}
+	return i18n.Fallback
+}
+
+// guildLocale returns the locale of messages sent to a guild's channels rather than to a user: the guild's
+// preferred locale if the catalog is translated into it, else the fallback.
+func (h *Handler) guildLocale(guildID snowflake.ID) discord.Locale {
+	if guild, ok := h.Client.Caches().Guild(guildID); ok && i18n.Supported(discord.Locale(guild.PreferredLocale)) {
+		return discord.Locale(guild.PreferredLocale)
+	}
+	return i18n.Fallback
+}
+
+// commandLocalizationID returns the prefix of the catalog IDs of a command's translations, like
+// "command.play_this_link".
+func commandLocalizationID(name string) string {
+	return "command." + strings.ReplaceAll(strings.ToLower(name), " ", "_")
+}
+
+// localizeOptions returns options with the translated descriptions of the catalog entries below prefix,
+// recursing into subcommands. Option names are not translated, like slash command names.
+func localizeOptions(prefix string, options []discord.ApplicationCommandOption) []discord.ApplicationCommandOption {
+	if len(options) == 0 {
+		return nil
+	}
+	localizedOptions := make([]discord.ApplicationCommandOption, 0, len(options))
+	for _, option := range options {
+		id := prefix + "." + option.OptionName()
+		descriptions := i18n.Localizations(id + ".description")
+		switch option := option.(type) {
+		case discord.ApplicationCommandOptionSubCommandGroup:
+			option.DescriptionLocalizations = descriptions
+			subCommands := make([]discord.ApplicationCommandOptionSubCommand, len(option.Options))
+			for i, subCommand := range option.Options {
+				subCommand.DescriptionLocalizations = i18n.Localizations(id + "." + subCommand.Name + ".description")
+				subCommand.Options = localizeOptions(id+"."+subCommand.Name, subCommand.Options)
+				subCommands[i] = subCommand
+			}
+			option.Options = subCommands
+			localizedOptions = append(localizedOptions, option)
+		case discord.ApplicationCommandOptionSubCommand:
+			option.DescriptionLocalizations = descriptions
+			option.Options = localizeOptions(id, option.Options)
+			localizedOptions = append(localizedOptions, option)
+		case discord.ApplicationCommandOptionString:
+			option.DescriptionLocalizations = descriptions
+			localizedOptions = append(localizedOptions, option)
+		case discord.ApplicationCommandOptionInt:
+			option.DescriptionLocalizations = descriptions
+			localizedOptions = append(localizedOptions, option)
+		case discord.ApplicationCommandOptionBool:
+			option.DescriptionLocalizations = descriptions
+			localizedOptions = append(localizedOptions, option)
+		case discord.ApplicationCommandOptionUser:
+			option.DescriptionLocalizations = descriptions
+			localizedOptions = append(localizedOptions, option)
+		case discord.ApplicationCommandOptionChannel:
+			option.DescriptionLocalizations = descriptions
+			localizedOptions = append(localizedOptions, option)
+		default:
+			localizedOptions = append(localizedOptions, option)
+		}
+	}
+	return localizedOptions
+}
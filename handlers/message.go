@@ -1,12 +1,16 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
 	"log/slog"
+	"regexp"
+	"slices"
 	"strings"
+	"unccord-bot-go/config"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
 	"github.com/disgoorg/disgolink/v3/lavalink"
 )
 
@@ -15,39 +19,122 @@ type PlayerInfo struct {
 	IsPlaying bool
 }
 
-func (h *Handler) OnMessageCreate(event *events.MessageCreate) {
-	if event.Message.Author.Bot {
+// Reactions added to a message whose links were played.
+const (
+	reactionQueued = "✅"
+	reactionFailed = "❌"
+)
+
+// linkPattern matches the http(s) links in a message. Parentheses and angle brackets end a link so that
+// Markdown links and suppressed embeds are picked up without their delimiters.
+var linkPattern = regexp.MustCompile(`https?://[^\s<>()]+`)
+
+// OnMessageCreate plays the links and audio files posted in a music channel by a member in voice. The
+// message gets a reaction instead of a reply: ✅ if something was queued and ❌ if something failed. A link
+// that starts playback is announced with the Now Playing message and control panel, as with /play. Every
+// link takes a use of the rate limit; once it is used up the remaining links are dropped and the author
+// gets a reply, so they know when to try again.
+func (h *Handler) OnMessageCreate(ctx context.Context, event *events.MessageCreate) {
+	guildID := event.Message.GuildID
+	if event.Message.Author.Bot || guildID == nil || !slices.Contains(config.Get().Music.Channels, event.ChannelID) {
 		return
 	}
 
-	content := event.Message.Content
-	if strings.HasPrefix(content, "http://") || strings.HasPrefix(content, "https://") {
-		guildID := event.Message.GuildID
-		if guildID == nil {
-			return
-		}
+	links := messageLinks(event.Message)
+	if len(links) == 0 {
+		return
+	}
+
+	voiceState, exists := h.Client.Caches().VoiceState(*guildID, event.Message.Author.ID)
+	if !exists || voiceState.ChannelID == nil {
+		return
+	}
 
-		voiceState, exists := h.Client.Caches().VoiceState(*guildID, event.Message.Author.ID)
-		if !exists || voiceState.ChannelID == nil {
-			return
+	if !h.musicAvailable() {
+		h.react(ctx, event.Message, reactionFailed)
+		return
+	}
+
+	var member *discord.ResolvedMember
+	if author, ok := h.messageAuthor(event.Message); ok {
+		member = &author
+	}
+	bypass := bypassRateLimit(member)
+
+	var queued, failed bool
+	for _, link := range links {
+		if !bypass {
+			if ok, wait := h.limiter.allow(rateLimitPlayLink, event.Message.Author.ID, guildID); !ok {
+				reply := &messageReply{client: event.Client(), message: event.Message, locale: h.guildLocale(*guildID)}
+				replyError(reply, errCooldown(wait))
+				failed = true
+				break
+			}
 		}
 
-		err := h.play(*guildID, event.ChannelID, *voiceState.ChannelID, content)
+		tracks, started, err := h.play(ctx, *guildID, *voiceState.ChannelID, link, event.Message.Author.ID)
 		if err != nil {
-			slog.Error("Failed to play track", slog.Any("err", err))
-			embed := discord.NewEmbedBuilder().
-				SetTitle("Error").
-				SetDescription(fmt.Sprintf("Failed to play the track: %v", err)).
-				SetColor(ColorError).
-				Build()
-
-			_, sendErr := h.Client.Rest().CreateMessage(event.ChannelID, discord.NewMessageCreateBuilder().
-				SetEmbeds(embed).
-				SetEphemeral(true).
-				Build())
-			if sendErr != nil {
-				slog.Error("Failed to send error message", slog.Any("err", sendErr))
-			}
+			slog.Warn("Failed to play posted link", slog.Any("err", err), "link", link, "guildID", *guildID)
+			failed = true
+			continue
+		}
+		queued = true
+		// Links that only join the queue are acknowledged by the reaction alone
+		if started {
+			h.announceQueued(ctx, *guildID, event.ChannelID, tracks, started)
+		}
+	}
+	if queued {
+		h.react(ctx, event.Message, reactionQueued)
+	}
+	if failed {
+		h.react(ctx, event.Message, reactionFailed)
+	}
+}
+
+// messageLinks returns the links and audio or video attachments of a message, without duplicates.
+func messageLinks(message discord.Message) []string {
+	var links []string
+	for _, link := range linkPattern.FindAllString(message.Content, -1) {
+		if !slices.Contains(links, link) {
+			links = append(links, link)
 		}
 	}
+	for _, attachment := range message.Attachments {
+		if attachment.ContentType == nil {
+			continue
+		}
+		if strings.HasPrefix(*attachment.ContentType, "audio/") || strings.HasPrefix(*attachment.ContentType, "video/") {
+			links = append(links, attachment.URL)
+		}
+	}
+	return links
+}
+
+// messageAuthor resolves the author of a guild message with their permissions in the message's channel,
+// like the member of an interaction. Threads have the permissions of their parent channel. It reports false
+// if the author or the channel is not known, so callers fail closed.
+func (h *Handler) messageAuthor(message discord.Message) (discord.ResolvedMember, bool) {
+	if message.Member == nil || message.GuildID == nil {
+		return discord.ResolvedMember{}, false
+	}
+
+	channelID := message.ChannelID
+	if thread, ok := h.Client.Caches().GuildThread(channelID); ok && thread.ParentID() != nil {
+		channelID = *thread.ParentID()
+	}
+	channel, ok := h.Client.Caches().Channel(channelID)
+	if !ok {
+		return discord.ResolvedMember{}, false
+	}
+
+	member := messageMember(message)
+	return discord.ResolvedMember{Member: member, Permissions: h.Client.Caches().MemberPermissionsInChannel(channel, member)}, true
+}
+
+// react adds a reaction to a message.
+func (h *Handler) react(ctx context.Context, message discord.Message, emoji string) {
+	if err := h.Client.Rest().AddReaction(message.ChannelID, message.ID, emoji, rest.WithCtx(ctx)); err != nil {
+		slog.Error("Failed to add reaction", slog.Any("err", err), "messageID", message.ID)
+	}
 }
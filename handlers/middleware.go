@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"unccord-bot-go/config"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// commandMiddleware returns the middleware every command runs through, outermost first.
+func (h *Handler) commandMiddleware() []Middleware {
+	return []Middleware{
+		logCommands,
+		recoverCommands,
+		checkPermissions,
+		h.requireMusic,
+		h.rateLimit,
+	}
+}
+
+// logCommands logs who used a command and how long it took.
+func logCommands(cmd Command, next CommandHandler) CommandHandler {
+	return func(ctx context.Context, event *CommandEvent) {
+		start := time.Now()
+		next(ctx, event)
+		attrs := []any{"command", cmd.Name, "userID", event.User().ID, "duration", time.Since(start)}
+		if guildID := event.GuildID(); guildID != nil {
+			attrs = append(attrs, "guildID", *guildID)
+		}
+		slog.Info("Command handled", attrs...)
+	}
+}
+
+// recoverCommands turns a panic in a command into an error reply, logged with the command's name.
+func recoverCommands(cmd Command, next CommandHandler) CommandHandler {
+	return func(ctx context.Context, event *CommandEvent) {
+		defer func() {
+			if r := recover(); r != nil {
+				recoverPanic(event.ApplicationCommandInteractionCreate, r, "command", cmd.Name)
+			}
+		}()
+		next(ctx, event)
+	}
+}
+
+// checkPermissions rejects commands used outside a guild when they are guild only, and commands used by
+// members without the permissions they require.
+func checkPermissions(cmd Command, next CommandHandler) CommandHandler {
+	return func(ctx context.Context, event *CommandEvent) {
+		if cmd.GuildOnly && event.GuildID() == nil {
+			replyError(event, errGuildOnly)
+			return
+		}
+		if cmd.Permissions != 0 {
+			member := event.Member()
+			if member == nil || !member.Permissions.Has(cmd.Permissions) {
+				replyError(event, errPermissionDenied(cmd.Permissions))
+				return
+			}
+		}
+		next(ctx, event)
+	}
+}
+
+// requireMusic rejects music commands while no Lavalink node is connected.
+func (h *Handler) requireMusic(cmd Command, next CommandHandler) CommandHandler {
+	if !cmd.Music {
+		return next
+	}
+	return func(ctx context.Context, event *CommandEvent) {
+		if !h.musicAvailable() {
+			replyError(event, errMusicUnavailable)
+			return
+		}
+		next(ctx, event)
+	}
+}
+
+// rateLimit rejects commands used while the user's or the guild's bucket for the command is empty.
+// Administrators are exempt when rate_limits.bypass_admins is set.
+func (h *Handler) rateLimit(cmd Command, next CommandHandler) CommandHandler {
+	return func(ctx context.Context, event *CommandEvent) {
+		if !bypassRateLimit(event.Member()) {
+			if ok, wait := h.limiter.allow(cmd.Name, event.User().ID, event.GuildID()); !ok {
+				replyError(event, errCooldown(wait))
+				return
+			}
+		}
+		next(ctx, event)
+	}
+}
+
+// bypassRateLimit reports whether member is exempt from rate limits. member is nil outside guilds.
+func bypassRateLimit(member *discord.ResolvedMember) bool {
+	return member != nil && config.Get().RateLimits.BypassAdmins && member.Permissions.Has(discord.PermissionAdministrator)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"unccord-bot-go/config"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// Module is a feature of the bot that can be enabled in the config. It declares what it needs from
+// Discord and its commands, and receives the gateway events while it is enabled. Command interactions
+// are routed through the command registry instead of OnEvent.
+type Module struct {
+	Name     string
+	Intents  gateway.Intents
+	Caches   cache.Flags
+	Commands []Command
+	OnEvent  func(ctx context.Context, event bot.Event)
+}
+
+// baseIntents are needed by the bot regardless of the enabled modules. Text commands are read from
+// guild messages.
+const baseIntents = gateway.IntentGuilds | gateway.IntentGuildMessages | gateway.IntentMessageContent
+
+// baseCaches are needed by the bot regardless of the enabled modules. Text commands are answered in the
+// guild's preferred locale and checked against the author's permissions in the channel, which take the
+// channel's overwrites into account.
+const baseCaches = cache.FlagGuilds | cache.FlagRoles | cache.FlagChannels
+
+// loadModules builds the modules enabled in the config.
+func (h *Handler) loadModules() []Module {
+	var modules []Module
+	if config.Get().Modules.Music {
+		modules = append(modules, h.musicModule())
+	}
+	if config.Get().Modules.Starboard {
+		modules = append(modules, h.starboardModule())
+	}
+	return modules
+}
+
+func (h *Handler) musicModule() Module {
+	return Module{
+		Name:    "music",
+		Intents: gateway.IntentGuildVoiceStates | gateway.IntentGuildMessages | gateway.IntentMessageContent,
+		// Guilds are cached for their preferred locale, used for messages to the channel
+		Caches:   cache.FlagVoiceStates | cache.FlagChannels | cache.FlagGuilds,
+		Commands: append(h.musicCommands(), h.musicContextMenus()...),
+		OnEvent: func(ctx context.Context, event bot.Event) {
+			switch e := event.(type) {
+			case *events.MessageCreate:
+				h.OnMessageCreate(ctx, e)
+			case *events.GuildVoiceStateUpdate:
+				h.OnVoiceStateUpdate(ctx, e)
+			case *events.VoiceServerUpdate:
+				h.OnVoiceServerUpdate(ctx, e)
+			case *events.ComponentInteractionCreate:
+				h.OnComponentInteraction(ctx, e)
+			case *events.GuildReady:
+				h.OnGuildReady(ctx, e)
+			}
+		},
+	}
+}
+
+func (h *Handler) starboardModule() Module {
+	return Module{
+		Name: "starboard",
+		// Message content is needed to copy starred messages onto the starboard
+		Intents:  gateway.IntentGuildMessageReactions | gateway.IntentGuildMessages | gateway.IntentMessageContent,
+		Commands: h.starboardContextMenus(),
+		OnEvent: func(ctx context.Context, event bot.Event) {
+			switch e := event.(type) {
+			case *events.GuildMessageReactionAdd:
+				h.OnReactionAdd(ctx, e)
+			case *events.GuildMessageReactionRemove:
+				h.OnReactionRemove(ctx, e)
+			}
+		},
+	}
+}
+
+// Modules returns the enabled modules.
+func (h *Handler) Modules() []Module {
+	return h.modules
+}
+
+// Intents returns the gateway intents required by the enabled modules.
+func (h *Handler) Intents() gateway.Intents {
+	intents := baseIntents
+	for _, module := range h.modules {
+		intents |= module.Intents
+	}
+	return intents
+}
+
+// Caches returns the cache flags required by the enabled modules.
+func (h *Handler) Caches() cache.Flags {
+	flags := baseCaches
+	for _, module := range h.modules {
+		flags |= module.Caches
+	}
+	return flags
+}
+
+// loadCommands builds the registry of the enabled modules' commands and the commands every deployment has.
+func (h *Handler) loadCommands() *CommandRegistry {
+	registry := NewCommandRegistry()
+	registry.Use(h.commandMiddleware()...)
+	registry.Register(h.prefixCommands()...)
+	for _, module := range h.modules {
+		registry.Register(module.Commands...)
+	}
+	return registry
+}
+
+// Commands returns the application command definitions of the enabled modules.
+func (h *Handler) Commands() []discord.ApplicationCommandCreate {
+	return h.commands.Definitions()
+}
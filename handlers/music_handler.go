@@ -1,352 +1,316 @@
 package handlers
 
 import (
-    "context"
-    "fmt"
-    "log/slog"
-
-    "github.com/disgoorg/disgo/discord"
-    "github.com/disgoorg/disgo/events"
-    "github.com/disgoorg/disgolink/v3/disgolink"
-    "github.com/disgoorg/disgolink/v3/lavalink"
-    "github.com/disgoorg/snowflake/v2"
+	"context"
+	"fmt"
+	"log/slog"
+	"unccord-bot-go/i18n"
+	"unccord-bot-go/queue"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
 )
 
-func (h *Handler) OnComponentInteraction(event *events.ComponentInteractionCreate) {
-    if event.GuildID() == nil {
-        return
-    }
-
-    switch event.Data.CustomID() {
-    case "playpause":
-        h.handlePlayPause(event)
-    case "skip":
-        h.handleSkipButton(event)
-    case "rewind":
-        h.handleRewind(event)
-    }
+// play joins the voice channel and queues the tracks url resolves to for requesterID, starting playback if
+// nothing is playing. It returns the added tracks and whether one of them started playing; telling anyone
+// about them is left to the caller.
+func (h *Handler) play(ctx context.Context, guildID, voiceChannelID snowflake.ID, url string, requesterID snowflake.ID) ([]lavalink.Track, bool, error) {
+	err := h.Client.UpdateVoiceState(ctx, guildID, &voiceChannelID, false, false)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to join voice channel: %w", err)
+	}
+
+	node, err := h.loadNode()
+	if err != nil {
+		return nil, false, err
+	}
+
+	queue := h.Queues.Get(guildID)
+	player, err := h.playerFor(guildID, voiceChannelID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var loadError error
+	var trackLoaded bool
+	var addedTracks []lavalink.Track
+	var started bool
+
+	isPlaying := player != nil && player.Track() != nil
+
+	startIfNeeded := func(track lavalink.Track) {
+		if !isPlaying {
+			err := h.playTrack(ctx, guildID, track)
+			if err != nil {
+				slog.Error("Failed to play track", slog.Any("err", err))
+			} else {
+				trackLoaded = true
+				isPlaying = true
+				started = true
+			}
+		} else {
+			queue.Add(track)
+			slog.Info("Added track to queue", "track", track.Info.Title, "position", queue.Len(), "guildID", guildID)
+			trackLoaded = true
+		}
+	}
+
+	node.LoadTracksHandler(ctx, url, disgolink.NewResultHandler(
+		func(track lavalink.Track) {
+			slog.Info("Single track loaded", "title", track.Info.Title, "guildID", guildID)
+			track = withRequester(track, requesterID)
+			addedTracks = append(addedTracks, track)
+			startIfNeeded(track)
+		},
+		func(playlist lavalink.Playlist) {
+			slog.Info("Playlist loaded", "trackCount", len(playlist.Tracks), "guildID", guildID)
+			for i := range playlist.Tracks {
+				playlist.Tracks[i] = withRequester(playlist.Tracks[i], requesterID)
+			}
+			addedTracks = append(addedTracks, playlist.Tracks...)
+			for _, track := range playlist.Tracks {
+				startIfNeeded(track)
+			}
+		},
+		func(tracks []lavalink.Track) {
+			slog.Info("Search results loaded", "trackCount", len(tracks), "guildID", guildID)
+			if len(tracks) > 0 {
+				for i := range tracks {
+					tracks[i] = withRequester(tracks[i], requesterID)
+				}
+				addedTracks = append(addedTracks, tracks...)
+				for _, track := range tracks {
+					startIfNeeded(track)
+				}
+			}
+			trackLoaded = true
+		},
+		func() {
+			slog.Info("No matches found", "url", url, "guildID", guildID)
+			loadError = errLoadFailed(url, nil)
+		},
+		func(err error) {
+			slog.Warn("Error loading track", slog.Any("err", err), "url", url, "guildID", guildID)
+			loadError = errLoadFailed(url, err)
+		},
+	))
+
+	if loadError != nil {
+		return nil, false, loadError
+	}
+
+	if !trackLoaded {
+		return nil, false, fmt.Errorf("no track loaded for URL: %s", url)
+	}
+
+	return addedTracks, started, nil
 }
 
-func (h *Handler) play(guildID, commandChannelID, voiceChannelID snowflake.ID, url string) error {
-    err := h.Client.UpdateVoiceState(context.Background(), guildID, &voiceChannelID, false, false)
-    if err != nil {
-        return fmt.Errorf("failed to join voice channel: %w", err)
-    }
-
-    queue := h.Queues.Get(guildID)
-    player := h.Lavalink.Player(guildID)
-
-    var loadError error
-    var trackLoaded bool
-    var addedTracks []lavalink.Track
-
-    isPlaying := player != nil && player.Track() != nil
-
-    startIfNeeded := func(track lavalink.Track) {
-        if !isPlaying {
-            err := h.playTrack(guildID, track)
-            if err != nil {
-                slog.Error("Failed to play track", slog.Any("err", err))
-            } else {
-                trackLoaded = true
-                isPlaying = true
-                // Create the player control panel after the track starts playing
-                go h.createControlPanel(commandChannelID, guildID)
-            }
-        } else {
-            queue.Add(track)
-            slog.Info("Added track to queue", "track", track.Info.Title, "position", len(queue.Tracks), "guildID", guildID)
-            trackLoaded = true
-        }
-    }
-
-    h.Lavalink.BestNode().LoadTracksHandler(context.TODO(), url, disgolink.NewResultHandler(
-        func(track lavalink.Track) {
-            slog.Info("Single track loaded", "title", track.Info.Title, "guildID", guildID)
-            addedTracks = append(addedTracks, track)
-            startIfNeeded(track)
-        },
-        func(playlist lavalink.Playlist) {
-            slog.Info("Playlist loaded", "trackCount", len(playlist.Tracks), "guildID", guildID)
-            addedTracks = append(addedTracks, playlist.Tracks...)
-            for _, track := range playlist.Tracks {
-                queue.Add(track)
-            }
-            if len(playlist.Tracks) > 0 {
-                startIfNeeded(playlist.Tracks[0])
-            }
-        },
-        func(tracks []lavalink.Track) {
-            slog.Info("Search results loaded", "trackCount", len(tracks), "guildID", guildID)
-            if len(tracks) > 0 {
-                addedTracks = append(addedTracks, tracks...)
-                for _, track := range tracks {
-                    queue.Add(track)
-                }
-                startIfNeeded(tracks[0])
-            }
-            trackLoaded = true
-        },
-        func() {
-            slog.Error("No matches found", "url", url, "guildID", guildID)
-            loadError = fmt.Errorf("no matches found for URL: %s", url)
-        },
-        func(err error) {
-            slog.Error("Error loading track", slog.Any("err", err), "url", url, "guildID", guildID)
-            loadError = fmt.Errorf("error loading track: %w", err)
-        },
-    ))
-
-    if loadError != nil {
-        return loadError
-    }
-
-    if !trackLoaded {
-        return fmt.Errorf("no track loaded for URL: %s", url)
-    }
-
-    // Send appropriate message based on queue position
-    var embed *discord.EmbedBuilder
-    if len(addedTracks) == 1 {
-        track := addedTracks[0]
-        if !isPlaying {
-            embed = discord.NewEmbedBuilder().
-                SetTitle("Now Playing").
-                SetDescription(fmt.Sprintf("**%s**", track.Info.Title)).
-                SetColor(ColorSuccess).
-                SetThumbnail(*track.Info.ArtworkURL)
-        } else {
-            embed = discord.NewEmbedBuilder().
-                SetTitle("Added to Queue").
-                SetDescription(fmt.Sprintf("**%s**\n\nPosition in queue: %d",
-                    track.Info.Title,
-                    len(queue.Tracks))).
-                SetColor(ColorInfo).
-                SetThumbnail(*track.Info.ArtworkURL)
-        }
-    } else {
-        embed = discord.NewEmbedBuilder().
-            SetTitle("Playlist Added to Queue").
-            SetDescription(fmt.Sprintf("Added %d tracks to the queue", len(addedTracks))).
-            SetColor(ColorInfo)
-    }
-
-    _, err = h.Client.Rest().CreateMessage(commandChannelID, discord.NewMessageCreateBuilder().
-        SetEmbeds(embed.Build()).
-        Build())
-    if err != nil {
-        slog.Error("Failed to send queue message", slog.Any("err", err))
-    }
-
-    return nil
+// announceQueued tells a channel about the tracks play added and, if one of them started playing, creates
+// the player control panel there.
+func (h *Handler) announceQueued(ctx context.Context, guildID, channelID snowflake.ID, addedTracks []lavalink.Track, started bool) {
+	// The message goes to the channel, so it is in the guild's locale
+	locale := h.guildLocale(guildID)
+	var embed *discord.EmbedBuilder
+	if len(addedTracks) == 1 {
+		track := addedTracks[0]
+		if started {
+			embed = trackEmbed(track).
+				SetTitle(i18n.Text(locale, "music.now_playing.title")).
+				SetDescription(fmt.Sprintf("**%s**", track.Info.Title)).
+				SetColor(ColorSuccess)
+		} else {
+			embed = trackEmbed(track).
+				SetTitle(i18n.Text(locale, "music.added.title")).
+				SetDescription(i18n.Text(locale, "music.added", track.Info.Title, h.Queues.Get(guildID).Len())).
+				SetColor(ColorInfo)
+		}
+	} else {
+		embed = discord.NewEmbedBuilder().
+			SetTitle(i18n.Text(locale, "music.playlist_added.title")).
+			SetDescription(i18n.Text(locale, "music.playlist_added", len(addedTracks))).
+			SetColor(ColorInfo)
+	}
+
+	_, err := h.Client.Rest().CreateMessage(channelID, discord.NewMessageCreateBuilder().
+		SetEmbeds(embed.Build()).
+		Build(), rest.WithCtx(ctx))
+	if err != nil {
+		slog.Error("Failed to send queue message", slog.Any("err", err))
+	}
+
+	if started {
+		// The control panel outlives the interaction, so it gets its own context
+		go h.runTask("control panel", func(ctx context.Context) {
+			h.createControlPanel(ctx, channelID, guildID)
+		}, "guildID", guildID)
+	}
 }
 
-func (h *Handler) createControlPanel(channelID, guildID snowflake.ID) {
-    player := h.Lavalink.ExistingPlayer(guildID)
-    if player == nil {
-        slog.Error("No active player found", "guildID", guildID)
-        return
-    }
-
-    currentTrack := player.Track()
-    if currentTrack == nil {
-        slog.Error("No current track found", "guildID", guildID)
-        return
-    }
-
-    queue := h.Queues.Get(guildID)
-    queueInfo := fmt.Sprintf("Next in queue: %d", len(queue.Tracks))
-
-    _, err := h.Client.Rest().CreateMessage(channelID, discord.NewMessageCreateBuilder().
-        SetContent("").
-        SetEmbeds(discord.NewEmbedBuilder().
-            SetTitle("Now Playing").
-            SetDescription(fmt.Sprintf("**%s**\nby *%s*\n\n%s", currentTrack.Info.Title, currentTrack.Info.Author, queueInfo)).
-            SetColor(ColorInfo).
-            SetThumbnail(*currentTrack.Info.ArtworkURL).
-            Build(),
-        ).
-        AddActionRow(
-            discord.NewSecondaryButton("⏪ Rewind", "rewind"),
-            discord.NewPrimaryButton("⏯️ Play/Pause", "playpause"),
-            discord.NewSecondaryButton("⏩ Skip", "skip"),
-        ).
-        Build(),
-    )
-
-    if err != nil {
-        slog.Error("Failed to create control panel", slog.Any("err", err))
-    } else {
-        slog.Info("Control panel created successfully", "guildID", guildID)
-    }
+// withRequester labels a track with the user who queued it.
+func withRequester(track lavalink.Track, requesterID snowflake.ID) lavalink.Track {
+	labelled, err := track.WithUserData(queue.TrackData{RequesterID: requesterID})
+	if err != nil {
+		slog.Error("Failed to label track with its requester", slog.Any("err", err))
+		return track
+	}
+	return labelled
 }
 
-func (h *Handler) handlePlayPause(event *events.ComponentInteractionCreate) {
-    player := h.Lavalink.Player(*event.GuildID())
-    if player == nil {
-        _ = event.CreateMessage(discord.NewMessageCreateBuilder().SetContent("No active player found.").SetEphemeral(true).Build())
-        return
-    }
-
-    var action string
-    if player.Paused() {
-        _ = player.Update(context.TODO(), lavalink.WithPaused(false))
-        action = "resumed"
-    } else {
-        _ = player.Update(context.TODO(), lavalink.WithPaused(true))
-        action = "paused"
-    }
-
-    _ = event.CreateMessage(discord.NewMessageCreateBuilder().SetContent(fmt.Sprintf("Playback %s.", action)).SetEphemeral(true).Build())
+// loadTracks resolves an identifier into the tracks it refers to without queueing them.
+func (h *Handler) loadTracks(ctx context.Context, identifier string) ([]lavalink.Track, error) {
+	node, err := h.loadNode()
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []lavalink.Track
+	var loadError error
+	node.LoadTracksHandler(ctx, identifier, disgolink.NewResultHandler(
+		func(track lavalink.Track) {
+			tracks = []lavalink.Track{track}
+		},
+		func(playlist lavalink.Playlist) {
+			tracks = playlist.Tracks
+		},
+		func(results []lavalink.Track) {
+			tracks = results
+		},
+		func() {
+			loadError = fmt.Errorf("no matches found for: %s", identifier)
+		},
+		func(err error) {
+			loadError = fmt.Errorf("error loading track: %w", err)
+		},
+	))
+	return tracks, loadError
 }
 
-func (h *Handler) handleRewind(event *events.ComponentInteractionCreate) {
-    player := h.Lavalink.Player(*event.GuildID())
-    if player == nil {
-        _ = event.CreateMessage(discord.NewMessageCreateBuilder().SetContent("No active player found.").SetEphemeral(true).Build())
-        return
-    }
-
-    currentPosition := player.Position()
-    newPosition := currentPosition - lavalink.Duration(10_000*lavalink.Millisecond)
-    if newPosition < 0 {
-        newPosition = 0
-    }
-
-    _ = player.Update(context.TODO(), lavalink.WithPosition(newPosition))
-    _ = event.CreateMessage(discord.NewMessageCreateBuilder().SetContent("Rewound 10 seconds.").SetEphemeral(true).Build())
+// OnTrackEnd starts the next queued track once the current one is done.
+func (h *Handler) OnTrackEnd(player disgolink.Player, event lavalink.TrackEndEvent) {
+	if !event.Reason.MayStartNext() {
+		return
+	}
+
+	guildID := player.GuildID()
+	if event.Reason == lavalink.TrackEndReasonFinished {
+		h.Queues.Get(guildID).Finished(event.Track)
+	}
+
+	slog.Info("Track ended, playing next track", "guildID", guildID, "reason", event.Reason)
+	// Use a goroutine to avoid blocking the Lavalink event loop
+	go h.runTask("track end", func(ctx context.Context) {
+		h.playNextTrack(ctx, guildID)
+	}, "guildID", guildID)
 }
 
-func (h *Handler) playNextTrack(guildID snowflake.ID) {
-    queue := h.Queues.Get(guildID)
-    if len(queue.Tracks) == 0 {
-        // If there are no more tracks, stop the player
-        player := h.Lavalink.ExistingPlayer(guildID)
-        if player != nil {
-            if err := player.Update(context.TODO(), lavalink.WithNullTrack()); err != nil {
-                slog.Error("Failed to stop player", slog.Any("err", err))
-            }
-        }
-        slog.Info("Queue ended, stopped player", "guildID", guildID)
-        return
-    }
-
-    nextTrack := queue.Tracks[0]
-    queue.Tracks = queue.Tracks[1:] // Remove the first track from the queue
-
-    player := h.Lavalink.Player(guildID)
-    err := player.Update(context.TODO(), lavalink.WithTrack(nextTrack), lavalink.WithPaused(false))
-    if err != nil {
-        slog.Error("Failed to play next track", slog.Any("err", err))
-        // If we fail to play this track, try the next one
-        h.playNextTrack(guildID)
-    } else {
-        slog.Info("Now playing next track", "title", nextTrack.Info.Title, "guildID", guildID)
-        // Re-add the track end listener for the new track
-        h.addTrackEndListener(player, guildID)
-        
-        // Ensure the player state is updated
-        updatedPlayer := h.Lavalink.ExistingPlayer(guildID)
-        if updatedPlayer != nil {
-            currentTrack := updatedPlayer.Track()
-            if currentTrack != nil {
-                slog.Info("Player state updated", "currentTrack", currentTrack.Info.Title, "guildID", guildID)
-            } else {
-                slog.Error("Player state not updated correctly", "guildID", guildID)
-            }
-        }
-    }
+func (h *Handler) playNextTrack(ctx context.Context, guildID snowflake.ID) {
+	queue := h.Queues.Get(guildID)
+	nextTrack, ok := queue.Next()
+	if !ok && h.refillFromPlaylist(ctx, guildID) {
+		nextTrack, ok = queue.Next()
+	}
+	if !ok {
+		nextTrack, ok = h.autoplayTrack(ctx, guildID)
+	}
+	if !ok {
+		if err := h.endQueue(ctx, guildID); err != nil {
+			slog.Error("Failed to stop player", slog.Any("err", err))
+		}
+		return
+	}
+
+	if err := h.playTrack(ctx, guildID, nextTrack); err != nil {
+		slog.Error("Failed to play next track", slog.Any("err", err))
+		if ctx.Err() != nil {
+			return
+		}
+		// If we fail to play this track, try the next one
+		h.playNextTrack(ctx, guildID)
+		return
+	}
+	slog.Info("Now playing next track", "title", nextTrack.Info.Title, "guildID", guildID)
 }
 
-func (h *Handler) addTrackEndListener(player disgolink.Player, guildID snowflake.ID) {
-    player.Node().Lavalink().AddListeners(disgolink.NewListenerFunc(func(p disgolink.Player, event lavalink.TrackEndEvent) {
-        if event.Reason == lavalink.TrackEndReasonFinished {
-            slog.Info("Track ended, playing next track", "guildID", guildID)
-            go h.playNextTrack(guildID)  // Use a goroutine to avoid blocking
-        }
-    }))
+// endQueue stops the player of a guild once there is nothing left to play, and starts the idle timer that
+// makes the bot leave voice.
+func (h *Handler) endQueue(ctx context.Context, guildID snowflake.ID) error {
+	if player := h.Lavalink.ExistingPlayer(guildID); player != nil {
+		if err := player.Update(ctx, lavalink.WithNullTrack()); err != nil {
+			return err
+		}
+	}
+	slog.Info("Queue ended, stopped player", "guildID", guildID)
+	h.startIdleTimer(guildID)
+	return nil
 }
 
-func (h *Handler) playTrack(guildID snowflake.ID, track lavalink.Track) error {
-    player := h.Lavalink.Player(guildID)
-    err := player.Update(context.TODO(), lavalink.WithTrack(track), lavalink.WithPaused(false))
-    if err != nil {
-        slog.Error("Error updating player", slog.Any("err", err))
-        return err
-    }
-
-    h.addTrackEndListener(player, guildID)
-
-    return nil
+func (h *Handler) playTrack(ctx context.Context, guildID snowflake.ID, track lavalink.Track) error {
+	// A new player goes to the node for the region of the channel the bot is in
+	var voiceChannelID snowflake.ID
+	if selfState, ok := h.Client.Caches().VoiceState(guildID, h.Client.ApplicationID()); ok && selfState.ChannelID != nil {
+		voiceChannelID = *selfState.ChannelID
+	}
+	player, err := h.playerFor(guildID, voiceChannelID)
+	if err != nil {
+		return err
+	}
+
+	err = player.Update(ctx, lavalink.WithTrack(track), lavalink.WithPaused(false))
+	if err != nil {
+		slog.Error("Error updating player", slog.Any("err", err))
+		return err
+	}
+
+	h.stopIdleTimer(guildID)
+	h.Queues.Get(guildID).Played(track)
+	return nil
 }
 
-func (h *Handler) handleSkipButton(event *events.ComponentInteractionCreate) {
-    player := h.Lavalink.ExistingPlayer(*event.GuildID())
-    if player == nil {
-        event.CreateMessage(discord.NewMessageCreateBuilder().
-            SetContent("No active player found.").
-            SetEphemeral(true).
-            Build())
-        return
-    }
-
-    h.playNextTrack(*event.GuildID())
-
-    currentTrack := player.Track()
-    if currentTrack == nil {
-        event.CreateMessage(discord.NewMessageCreateBuilder().
-            SetContent("Skipped the last track. The queue is now empty.").
-            SetEphemeral(true).
-            Build())
-        return
-    }
-
-    event.CreateMessage(discord.NewMessageCreateBuilder().
-        SetContent(fmt.Sprintf("Skipped to next track: **%s**", currentTrack.Info.Title)).
-        SetEphemeral(true).
-        Build())
+func (h *Handler) skipTracks(ctx context.Context, locale discord.Locale, guildID snowflake.ID, amount int) (*discord.EmbedBuilder, error) {
+	player := h.Lavalink.ExistingPlayer(guildID)
+	guildQueue := h.Queues.Get(guildID)
+	if player == nil {
+		return nil, errNoPlayer
+	}
+
+	// The current track counts as the first skipped track. While the whole queue loops, it goes to the end
+	// like a track that finished.
+	skippedTracks := min(max(amount, 1), guildQueue.Len()+1)
+	if current := player.Track(); current != nil && guildQueue.Loop() == queue.LoopQueue {
+		guildQueue.Add(*current)
+	}
+	guildQueue.Skip(skippedTracks - 1)
+
+	nextTrack, ok := guildQueue.Next()
+	if !ok {
+		// Skipping past the end ends the queue like the last track finishing
+		if err := h.endQueue(ctx, guildID); err != nil {
+			return nil, fmt.Errorf("error while stopping track: %w", err)
+		}
+		return discord.NewEmbedBuilder().
+			SetDescription(i18n.Text(locale, "music.skipped_all")).
+			SetColor(ColorInfo), nil
+	}
+
+	if err := h.playTrack(ctx, guildID, nextTrack); err != nil {
+		return nil, fmt.Errorf("error while skipping to next track: %w", err)
+	}
+
+	return trackEmbed(nextTrack).
+		SetTitle(i18n.Text(locale, "music.skipped.title")).
+		SetDescription(i18n.Count(locale, "music.skipped", skippedTracks, nextTrack.Info.Title)).
+		SetColor(ColorSuccess), nil
 }
 
-func (h *Handler) skipTracks(guildID snowflake.ID, amount int) (*discord.EmbedBuilder, error) {
-    player := h.Lavalink.ExistingPlayer(guildID)
-    queue := h.Queues.Get(guildID)
-    if player == nil {
-        return nil, fmt.Errorf("no player found")
-    }
-
-    skippedTracks := min(amount, len(queue.Tracks))
-    queue.Tracks = queue.Tracks[skippedTracks:]
-
-    if len(queue.Tracks) == 0 {
-        // If we've skipped all tracks, stop the player
-        if err := player.Update(context.TODO(), lavalink.WithNullTrack()); err != nil {
-            return nil, fmt.Errorf("error while stopping track: %w", err)
-        }
-        // Reset the player state
-        h.Lavalink.RemovePlayer(guildID)
-        return discord.NewEmbedBuilder().
-            SetDescription("Skipped all tracks. No more tracks in the queue. Stopped playing.").
-            SetColor(ColorInfo), nil
-    }
-
-    nextTrack := queue.Tracks[0]
-    if err := player.Update(context.TODO(), lavalink.WithTrack(nextTrack)); err != nil {
-        return nil, fmt.Errorf("error while skipping to next track: %w", err)
-    }
-
-    return discord.NewEmbedBuilder().
-        SetTitle("Skipped Track(s)").
-        SetDescription(fmt.Sprintf("Skipped %d %s.\n\nNow playing: **%s**",
-            skippedTracks,
-            pluralize("track", skippedTracks),
-            nextTrack.Info.Title)).
-        SetColor(ColorSuccess).
-        SetThumbnail(*nextTrack.Info.ArtworkURL), nil
+// trackEmbed starts an embed about a track, showing its artwork when the source provides one.
+func trackEmbed(track lavalink.Track) *discord.EmbedBuilder {
+	embed := discord.NewEmbedBuilder()
+	if track.Info.ArtworkURL != nil && *track.Info.ArtworkURL != "" {
+		embed.SetThumbnail(*track.Info.ArtworkURL)
+	}
+	return embed
 }
-
-func pluralize(word string, count int) string {
-    if count == 1 {
-        return word
-    }
-    return word + "s"
-}
\ No newline at end of file
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"testing"
+	"unccord-bot-go/queue"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const testGuildID = snowflake.ID(1)
+
+func testTrack(title string, artworkURL *string) lavalink.Track {
+	return lavalink.Track{
+		Encoded: "encoded-" + title,
+		Info: lavalink.TrackInfo{
+			Identifier: title,
+			Title:      title,
+			Author:     "Author",
+			ArtworkURL: artworkURL,
+			SourceName: "http",
+		},
+	}
+}
+
+func TestTrackEmbedArtwork(t *testing.T) {
+	empty, artwork := "", "https://example.com/artwork.jpg"
+	tests := []struct {
+		name          string
+		artworkURL    *string
+		wantThumbnail string
+	}{
+		{name: "no artwork", artworkURL: nil},
+		{name: "empty artwork", artworkURL: &empty},
+		{name: "artwork", artworkURL: &artwork, wantThumbnail: artwork},
+	}
+
+	h := &Handler{Queues: queue.NewQueueManager()}
+	for _, test := range tests {
+		track := testTrack(test.name, test.artworkURL)
+		embeds := map[string]discord.Embed{
+			"track":         trackEmbed(track).Build(),
+			"now playing":   nowPlayingEmbed(discord.LocaleEnglishUS, track).Build(),
+			"control panel": h.controlPanelEmbed(discord.LocaleEnglishUS, testGuildID, track).Build(),
+		}
+		for embedName, embed := range embeds {
+			t.Run(test.name+"/"+embedName, func(t *testing.T) {
+				thumbnail := ""
+				if embed.Thumbnail != nil {
+					thumbnail = embed.Thumbnail.URL
+				}
+				if thumbnail != test.wantThumbnail {
+					t.Errorf("thumbnail = %q, want %q", thumbnail, test.wantThumbnail)
+				}
+				if embed.Thumbnail != nil && embed.Thumbnail.URL == "" {
+					t.Error("embed has a thumbnail without a URL, which Discord rejects")
+				}
+			})
+		}
+	}
+}
+
+func TestQueueEmbedWithoutArtwork(t *testing.T) {
+	h := &Handler{Queues: queue.NewQueueManager(), Lavalink: disgolink.New(snowflake.ID(2))}
+	h.Queues.Get(testGuildID).Add(testTrack("first", nil), testTrack("second", nil))
+
+	embed := h.queueEmbed(discord.LocaleGerman, testGuildID).Build()
+	if embed.Thumbnail != nil {
+		t.Errorf("queue embed has thumbnail %+v, want none", embed.Thumbnail)
+	}
+	if embed.Description == "" {
+		t.Error("queue embed does not list the queued tracks")
+	}
+}
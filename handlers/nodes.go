@@ -0,0 +1,432 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unccord-bot-go/config"
+	"unccord-bot-go/i18n"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	// nodeFailoverGrace is how long a node may be disconnected before its players are moved to another node.
+	nodeFailoverGrace = 10 * time.Second
+	// nodeConnectTimeout bounds a single attempt to connect a node.
+	nodeConnectTimeout = 10 * time.Second
+	// nodeRetryMinDelay and nodeRetryMaxDelay bound the backoff between connection attempts.
+	nodeRetryMinDelay = time.Second
+	nodeRetryMaxDelay = time.Minute
+)
+
+// errNoNode is returned when no Lavalink node is connected.
+var errNoNode = errors.New("no Lavalink node is available")
+
+// voiceServer is the last voice server update received for a guild, needed to move its player between nodes.
+type voiceServer struct {
+	Token    string
+	Endpoint string
+}
+
+func (h *Handler) setVoiceServer(guildID snowflake.ID, server voiceServer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.voiceServers[guildID] = server
+}
+
+func (h *Handler) getVoiceServer(guildID snowflake.ID) (voiceServer, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	server, ok := h.voiceServers[guildID]
+	return server, ok
+}
+
+// nodeRegion returns the region configured for a Lavalink node.
+func nodeRegion(name string) string {
+	for _, node := range config.Get().Lavalink.Nodes {
+		if node.Name == name {
+			return node.Region
+		}
+	}
+	return ""
+}
+
+// nodeLoad estimates how busy a node is; lower is better.
+func nodeLoad(node disgolink.Node) int {
+	stats := node.Stats()
+	load := stats.PlayingPlayers
+	if stats.CPU.Cores > 0 {
+		load += int(stats.CPU.SystemLoad / float64(stats.CPU.Cores) * 100)
+	}
+	return load
+}
+
+// selectNode picks the least loaded connected node, preferring nodes serving the given voice region.
+// It returns nil if no node is connected.
+func (h *Handler) selectNode(region string, exclude disgolink.Node) disgolink.Node {
+	var best disgolink.Node
+	bestInRegion := false
+	h.Lavalink.ForNodes(func(node disgolink.Node) {
+		if node == exclude || node.Status() != disgolink.StatusConnected {
+			return
+		}
+
+		served := nodeRegion(node.Config().Name)
+		inRegion := region != "" && served != "" && strings.HasPrefix(region, served)
+		switch {
+		case best == nil,
+			inRegion && !bestInRegion,
+			inRegion == bestInRegion && nodeLoad(node) < nodeLoad(best):
+			best = node
+			bestInRegion = inRegion
+		}
+	})
+	return best
+}
+
+// musicAvailable reports whether at least one Lavalink node is connected.
+func (h *Handler) musicAvailable() bool {
+	return h.selectNode("", nil) != nil
+}
+
+// ConnectNodeWithRetry connects a Lavalink node, retrying with exponential backoff until it succeeds
+// or ctx is done. Once connected, disgolink takes care of reconnecting the node if it drops.
+func (h *Handler) ConnectNodeWithRetry(ctx context.Context, nodeConfig disgolink.NodeConfig) {
+	delay := nodeRetryMinDelay
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, nodeConnectTimeout)
+		node, err := h.ConnectNode(attemptCtx, nodeConfig)
+		var version string
+		if node != nil {
+			version, _ = node.Version(attemptCtx)
+		}
+		cancel()
+
+		if node != nil {
+			if err != nil {
+				slog.Warn("Lavalink node connected with errors", slog.Any("err", err), "node", nodeConfig.Name)
+			}
+			slog.Info("Lavalink node connected", "node", nodeConfig.Name, "version", version, "session_id", node.SessionID(), "attempt", attempt)
+			h.onNodeAvailable()
+			return
+		}
+
+		slog.Warn("Failed to connect Lavalink node, retrying", slog.Any("err", err), "node", nodeConfig.Name, "attempt", attempt, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, nodeRetryMaxDelay)
+	}
+}
+
+// lavalinkNodeConfig converts a configured node into the disgolink node config.
+func lavalinkNodeConfig(node config.LavalinkNode) disgolink.NodeConfig {
+	return disgolink.NodeConfig{
+		Name:     node.Name,
+		Address:  node.Address,
+		Password: node.Password.Value(),
+		Secure:   node.Secure,
+	}
+}
+
+// StartNode connects a configured Lavalink node in the background until it is connected,
+// StopNode is called for it or ctx is done.
+func (h *Handler) StartNode(ctx context.Context, node config.LavalinkNode) {
+	ctx, cancel := context.WithCancel(ctx)
+	h.mu.Lock()
+	h.nodeCancels[node.Name] = cancel
+	h.mu.Unlock()
+
+	go h.ConnectNodeWithRetry(ctx, lavalinkNodeConfig(node))
+}
+
+// StopNode stops connecting a Lavalink node, moves its players to the remaining nodes and removes it.
+// Players that cannot be moved are disconnected, as they would be left without a node.
+func (h *Handler) StopNode(ctx context.Context, name string) {
+	h.mu.Lock()
+	if cancel, ok := h.nodeCancels[name]; ok {
+		cancel()
+		delete(h.nodeCancels, name)
+	}
+	h.mu.Unlock()
+
+	node := h.Lavalink.Node(name)
+	if node == nil {
+		return
+	}
+	h.migratePlayers(ctx, node)
+	h.disconnectPlayers(ctx, node)
+	h.Lavalink.RemoveNode(name)
+	slog.Info("Lavalink node removed", "node", name)
+}
+
+// UpdateNodes applies a changed list of Lavalink nodes from a config reload: removed nodes are
+// drained and dropped, new nodes are connected and changed nodes are reconnected.
+func (h *Handler) UpdateNodes(ctx context.Context, old, next []config.LavalinkNode) {
+	previous := make(map[string]config.LavalinkNode, len(old))
+	for _, node := range old {
+		previous[node.Name] = node
+	}
+
+	kept := make(map[string]bool, len(next))
+	for _, node := range next {
+		kept[node.Name] = true
+		before, ok := previous[node.Name]
+		if ok && lavalinkNodeConfig(before) == lavalinkNodeConfig(node) {
+			continue
+		}
+		if ok {
+			h.StopNode(ctx, node.Name)
+		}
+		h.StartNode(ctx, node)
+	}
+
+	for _, node := range old {
+		if !kept[node.Name] {
+			h.StopNode(ctx, node.Name)
+		}
+	}
+}
+
+// onNodeAvailable finishes the guild setup and 24/7 rejoins that were postponed while no node was connected.
+func (h *Handler) onNodeAvailable() {
+	h.mu.Lock()
+	pending, rejoin := h.pendingGuilds, h.pendingRejoin
+	h.pendingGuilds = make(map[snowflake.ID]struct{})
+	h.pendingRejoin = make(map[snowflake.ID]struct{})
+	h.mu.Unlock()
+
+	for guildID := range rejoin {
+		if _, ok := pending[guildID]; ok {
+			// The guild setup joins the 24/7 channel as well
+			continue
+		}
+		slog.Info("Music is available again, rejoining 24/7 channel", "guildID", guildID)
+		h.runTask("24/7 rejoin", func(ctx context.Context) {
+			if err := h.joinAlwaysOn(ctx, guildID); err != nil {
+				slog.Error("Failed to rejoin 24/7 channel", slog.Any("err", err), "guildID", guildID)
+			}
+		}, "guildID", guildID)
+	}
+
+	for guildID := range pending {
+		slog.Info("Music is available again, restoring guild", "guildID", guildID)
+		h.runTask("guild setup", func(ctx context.Context) {
+			h.setupGuildMusic(ctx, guildID)
+		}, "guildID", guildID)
+	}
+}
+
+// loadNode returns the least loaded connected node for loading tracks.
+func (h *Handler) loadNode() (disgolink.Node, error) {
+	node := h.selectNode("", nil)
+	if node == nil {
+		return nil, errNoNode
+	}
+	return node, nil
+}
+
+// voiceRegion returns the RTC region of a voice channel, or an empty string if it is automatic or unknown.
+func (h *Handler) voiceRegion(channelID snowflake.ID) string {
+	channel, ok := h.Client.Caches().Channel(channelID)
+	if !ok {
+		return ""
+	}
+	if audioChannel, ok := channel.(discord.GuildAudioChannel); ok {
+		return audioChannel.RTCRegion()
+	}
+	return ""
+}
+
+// playerFor returns the player of a guild, creating it on the best node for the voice channel's region.
+// It returns errNoNode while no node is connected rather than creating a player without a node.
+func (h *Handler) playerFor(guildID, voiceChannelID snowflake.ID) (disgolink.Player, error) {
+	if player := h.Lavalink.ExistingPlayer(guildID); player != nil {
+		return player, nil
+	}
+
+	node := h.selectNode(h.voiceRegion(voiceChannelID), nil)
+	if node == nil {
+		return nil, errNoNode
+	}
+	return h.Lavalink.PlayerOnNode(node, guildID), nil
+}
+
+// MonitorNodes checks the Lavalink nodes every interval until ctx is done and moves the players of
+// nodes that stay disconnected longer than nodeFailoverGrace to a healthy node.
+func (h *Handler) MonitorNodes(ctx context.Context, interval time.Duration) {
+	downSince := make(map[string]time.Time)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var failed []disgolink.Node
+		recovered := false
+		h.Lavalink.ForNodes(func(node disgolink.Node) {
+			name := node.Config().Name
+			if node.Status() == disgolink.StatusConnected {
+				if _, ok := downSince[name]; ok {
+					slog.Info("Lavalink node reconnected", "node", name)
+					delete(downSince, name)
+					recovered = true
+				}
+				return
+			}
+			if _, ok := downSince[name]; !ok {
+				slog.Warn("Lavalink node disconnected", "node", name, "status", node.Status())
+				downSince[name] = time.Now()
+			}
+			if time.Since(downSince[name]) >= nodeFailoverGrace {
+				failed = append(failed, node)
+			}
+		})
+
+		for _, node := range failed {
+			h.migratePlayers(ctx, node)
+		}
+		if recovered {
+			h.onNodeAvailable()
+		}
+	}
+}
+
+// migratePlayers moves every player of a failed node to the best healthy node. Queues are kept
+// by the QueueManager independently of nodes, so only the playback state has to be moved.
+func (h *Handler) migratePlayers(ctx context.Context, failed disgolink.Node) {
+	var players []disgolink.Player
+	h.Lavalink.ForPlayers(func(player disgolink.Player) {
+		if player.Node() == failed {
+			players = append(players, player)
+		}
+	})
+
+	for _, player := range players {
+		guildID := player.GuildID()
+		target := h.selectNode(h.voiceRegionOf(player), failed)
+		if target == nil {
+			slog.Error("No healthy Lavalink node to move player to", "guildID", guildID, "node", failed.Config().Name)
+			continue
+		}
+
+		// Each move gets its own deadline so one stuck node does not hold up the others
+		migrateCtx, cancel := context.WithTimeout(ctx, eventTimeout)
+		err := h.migratePlayer(migrateCtx, player, target)
+		cancel()
+		if err != nil {
+			slog.Error("Failed to move player to another node", slog.Any("err", err), "guildID", guildID, "from", failed.Config().Name, "to", target.Config().Name)
+			continue
+		}
+		slog.Info("Moved player to another node", "guildID", guildID, "from", failed.Config().Name, "to", target.Config().Name)
+	}
+}
+
+// disconnectPlayers makes the guilds whose players are still on node leave voice. The players are dropped
+// even if the node cannot destroy them, since it is about to be removed.
+func (h *Handler) disconnectPlayers(ctx context.Context, node disgolink.Node) {
+	var guildIDs []snowflake.ID
+	h.Lavalink.ForPlayers(func(player disgolink.Player) {
+		if player.Node() == node {
+			guildIDs = append(guildIDs, player.GuildID())
+		}
+	})
+
+	for _, guildID := range guildIDs {
+		slog.Warn("Disconnecting player of removed Lavalink node", "guildID", guildID, "node", node.Config().Name)
+		if err := h.disconnect(ctx, guildID); err != nil {
+			slog.Error("Failed to disconnect player", slog.Any("err", err), "guildID", guildID)
+			h.Lavalink.RemovePlayer(guildID)
+			if err = h.Client.UpdateVoiceState(ctx, guildID, nil, false, false); err != nil {
+				slog.Error("Failed to leave voice channel", slog.Any("err", err), "guildID", guildID)
+			}
+		}
+	}
+}
+
+func (h *Handler) voiceRegionOf(player disgolink.Player) string {
+	if player.ChannelID() == nil {
+		return ""
+	}
+	return h.voiceRegion(*player.ChannelID())
+}
+
+// migratePlayer recreates a player on the target node, handing over its voice connection and playback state.
+func (h *Handler) migratePlayer(ctx context.Context, player disgolink.Player, target disgolink.Node) error {
+	guildID := player.GuildID()
+	server, ok := h.getVoiceServer(guildID)
+	if !ok {
+		return fmt.Errorf("no voice server known for guild")
+	}
+	selfState, ok := h.Client.Caches().VoiceState(guildID, h.Client.ApplicationID())
+	if !ok || selfState.ChannelID == nil {
+		return fmt.Errorf("bot is not connected to voice")
+	}
+
+	track := player.Track()
+	position := player.Position()
+	paused := player.Paused()
+	volume := player.Volume()
+	filters := player.Filters()
+
+	// Forget the player locally; the failed node cannot be asked to destroy it
+	h.Lavalink.RemovePlayer(guildID)
+	newPlayer := h.Lavalink.PlayerOnNode(target, guildID)
+	newPlayer.OnVoiceStateUpdate(ctx, selfState.ChannelID, selfState.SessionID)
+	newPlayer.OnVoiceServerUpdate(ctx, server.Token, server.Endpoint)
+
+	opts := []lavalink.PlayerUpdateOpt{lavalink.WithPaused(paused), lavalink.WithVolume(volume), lavalink.WithFilters(filters)}
+	if track != nil {
+		opts = append(opts, lavalink.WithTrack(*track), lavalink.WithPosition(position))
+	}
+	return newPlayer.Update(ctx, opts...)
+}
+
+func (h *Handler) handleNodes(ctx context.Context, event *CommandEvent) {
+	locale := interactionLocale(event)
+	embed := discord.NewEmbedBuilder().
+		SetTitle(i18n.Text(locale, "nodes.title")).
+		SetColor(ColorInfo)
+
+	h.Lavalink.ForNodes(func(node disgolink.Node) {
+		stats := node.Stats()
+		region := nodeRegion(node.Config().Name)
+		if region == "" {
+			region = i18n.Text(locale, "nodes.any_region")
+		}
+
+		cpu := 0.0
+		if stats.CPU.Cores > 0 {
+			cpu = stats.CPU.SystemLoad / float64(stats.CPU.Cores) * 100
+		}
+
+		embed.AddField(node.Config().Name, i18n.Text(locale, "nodes.status",
+			node.Status(),
+			region,
+			stats.Players,
+			stats.PlayingPlayers,
+			cpu,
+			stats.Memory.Used/1024/1024,
+			(time.Duration(stats.Uptime)*time.Millisecond).Truncate(time.Second),
+		), true)
+	})
+
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(embed.Build()).
+		SetEphemeral(true).
+		Build()); err != nil {
+		slog.Error("Failed to send node status", slog.Any("err", err))
+	}
+}
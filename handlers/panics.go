@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+)
+
+// panicCount counts the panics recovered while handling events, by event type or task. It is published
+// through expvar as "handler_panics", which the debug endpoint serves at /debug/vars when debug.addr is
+// set; each recovered panic also logs the count so far, so repeated panics show up in the logs without it.
+var panicCount = expvar.NewMap("handler_panics")
+
+// errCodeAlreadyAcknowledged is the Discord error for responding to an interaction a second time.
+const errCodeAlreadyAcknowledged rest.JSONErrorCode = 40060
+
+// recoverPanic handles a value recovered from a panic while handling event: it logs the panic with
+// its stack, counts it and, if the event is an interaction, tells the user that something went wrong.
+// It must be called from the deferred function that called recover.
+func recoverPanic(event any, r any, attrs ...any) {
+	eventType := fmt.Sprintf("%T", event)
+	logPanic("Recovered panic while handling event", eventType, r, append(attrs, "eventType", eventType)...)
+
+	if responder, ok := event.(messageResponder); ok {
+		replyPanic(responder, fmt.Errorf("panic handling %s: %v", eventType, r))
+	}
+}
+
+// logPanic logs a recovered panic with its stack and counts it under key.
+func logPanic(msg string, key string, r any, attrs ...any) {
+	panicCount.Add(key, 1)
+	var count int64
+	if counter, ok := panicCount.Get(key).(*expvar.Int); ok {
+		count = counter.Value()
+	}
+	slog.Error(msg, append(attrs, "panic", r, "panicCount", count, "stack", string(debug.Stack()))...)
+}
+
+// replyPanic tells the user of an interaction that handling it failed. Handlers that deferred their
+// response before panicking have already acknowledged the interaction, so their response is edited instead.
+func replyPanic(event messageResponder, err error) {
+	sendErr := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(errorEmbed(interactionLocale(event), err)).
+		SetEphemeral(true).
+		Build())
+
+	var restErr rest.Error
+	if deferred, ok := event.(deferrable); ok && errors.As(sendErr, &restErr) && restErr.Code == errCodeAlreadyAcknowledged {
+		response := &deferredResponse{ctx: context.Background(), event: deferred}
+		response.EditError(err)
+		return
+	}
+	if sendErr != nil {
+		slog.Error("Failed to send error message", slog.Any("err", sendErr))
+	}
+}
+
+// runTask runs work that happens outside an event, like a timer or a goroutine an event started, with its
+// own event context. A panic is logged and counted under task instead of crashing the bot.
+func (h *Handler) runTask(task string, f func(ctx context.Context), attrs ...any) {
+	ctx, cancel := h.eventContext()
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic("Recovered panic in background task", task, r, append(attrs, "task", task)...)
+		}
+	}()
+	f(ctx)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"expvar"
+	"testing"
+)
+
+// panickingEvent stands in for an event whose handler panicked. It is not an interaction, so there is
+// nobody to reply to.
+type panickingEvent struct{}
+
+func TestRecoverPanicCountsByEventType(t *testing.T) {
+	const eventType = "handlers.panickingEvent"
+	for range 2 {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					recoverPanic(panickingEvent{}, r)
+				}
+			}()
+			panic("boom")
+		}()
+	}
+
+	count, ok := panicCount.Get(eventType).(*expvar.Int)
+	if !ok || count.Value() != 2 {
+		t.Errorf("panic count of %q = %v, want 2", eventType, panicCount.Get(eventType))
+	}
+}
+
+func TestRunTaskRecoversPanic(t *testing.T) {
+	const task = "test task"
+	h := &Handler{ctx: context.Background()}
+
+	ran := false
+	h.runTask(task, func(ctx context.Context) {
+		ran = true
+		if ctx.Err() != nil {
+			t.Errorf("task context is done before the task ran: %v", ctx.Err())
+		}
+		panic("boom")
+	})
+
+	if !ran {
+		t.Fatal("task did not run")
+	}
+	count, ok := panicCount.Get(task).(*expvar.Int)
+	if !ok || count.Value() != 1 {
+		t.Errorf("panic count of %q = %v, want 1", task, panicCount.Get(task))
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"unccord-bot-go/storage"
+
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// snapshotPlayers captures the state of every player that is connected to a voice channel.
+func (h *Handler) snapshotPlayers() []storage.SavedPlayer {
+	var players []storage.SavedPlayer
+	h.Lavalink.ForPlayers(func(player disgolink.Player) {
+		if player.ChannelID() == nil {
+			return
+		}
+
+		guildQueue := h.Queues.Get(player.GuildID())
+		players = append(players, storage.SavedPlayer{
+			GuildID:        player.GuildID(),
+			VoiceChannelID: *player.ChannelID(),
+			Track:          player.Track(),
+			Position:       player.Position(),
+			Paused:         player.Paused(),
+			Volume:         player.Volume(),
+			Filters:        player.Filters(),
+			Loop:           guildQueue.Loop(),
+			Autoplay:       guildQueue.Autoplay(),
+			Queue:          guildQueue.Tracks(),
+		})
+	})
+	return players
+}
+
+// SavePlayerStates persists the state of all active players so they can be restored after a restart. Saved
+// states of guilds without a player, like those waiting for a Lavalink node, are kept until they are
+// restored or the bot leaves voice.
+func (h *Handler) SavePlayerStates(ctx context.Context) error {
+	players := h.snapshotPlayers()
+	if err := h.Store.SavePlayers(ctx, players); err != nil {
+		return fmt.Errorf("error saving player states: %w", err)
+	}
+	slog.Info("Saved player states", "playerCount", len(players))
+	return nil
+}
+
+// DestroyPlayers destroys every player on its Lavalink node. It is used on shutdown when the
+// session is not kept for resuming, so nodes do not keep playing to an empty voice connection.
+func (h *Handler) DestroyPlayers(ctx context.Context) {
+	var players []disgolink.Player
+	h.Lavalink.ForPlayers(func(player disgolink.Player) {
+		players = append(players, player)
+	})
+
+	for _, player := range players {
+		if err := player.Destroy(ctx); err != nil {
+			slog.Error("Failed to destroy player", slog.Any("err", err), "guildID", player.GuildID())
+		}
+	}
+	slog.Info("Destroyed players", "playerCount", len(players))
+}
+
+// PersistPlayerStates saves the player states every interval until ctx is done, limiting
+// what is lost if the bot is killed without a chance to save on shutdown.
+func (h *Handler) PersistPlayerStates(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			saveCtx, cancel := context.WithTimeout(ctx, eventTimeout)
+			if err := h.SavePlayerStates(saveCtx); err != nil {
+				slog.Error("Failed to persist player states", slog.Any("err", err))
+			}
+			cancel()
+		}
+	}
+}
+
+// restorePlayer rejoins voice and resumes playback from the persisted state of a guild.
+// It reports whether a state was restored.
+func (h *Handler) restorePlayer(ctx context.Context, guildID snowflake.ID) bool {
+	saved, ok, err := h.Store.SavedPlayer(ctx, guildID)
+	if err != nil {
+		slog.Error("Failed to load saved player state", slog.Any("err", err), "guildID", guildID)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	if err = h.Store.DeleteSavedPlayer(ctx, guildID); err != nil {
+		slog.Error("Failed to delete saved player state", slog.Any("err", err), "guildID", guildID)
+	}
+
+	guildQueue := h.Queues.Get(guildID)
+	guildQueue.Restore(saved.Queue, saved.Loop, saved.Autoplay)
+
+	if err = h.Client.UpdateVoiceState(ctx, guildID, &saved.VoiceChannelID, false, false); err != nil {
+		slog.Error("Failed to rejoin voice channel", slog.Any("err", err), "guildID", guildID)
+		return false
+	}
+
+	if player := h.Lavalink.ExistingPlayer(guildID); player != nil && player.Track() != nil {
+		// The Lavalink session was resumed and the player never stopped; rejoining voice is enough
+		slog.Info("Reattached to resumed player", "guildID", guildID, "track", player.Track().Info.Title)
+		return true
+	}
+
+	player, err := h.playerFor(guildID, saved.VoiceChannelID)
+	if err != nil {
+		slog.Error("Failed to create player", slog.Any("err", err), "guildID", guildID)
+		return false
+	}
+	if saved.Track == nil {
+		h.playNextTrack(ctx, guildID)
+		return true
+	}
+
+	err = player.Update(ctx,
+		lavalink.WithTrack(*saved.Track),
+		lavalink.WithPosition(saved.Position),
+		lavalink.WithPaused(saved.Paused),
+		lavalink.WithVolume(saved.Volume),
+		lavalink.WithFilters(saved.Filters),
+	)
+	if err != nil {
+		slog.Error("Failed to resume saved track", slog.Any("err", err), "guildID", guildID)
+		h.playNextTrack(ctx, guildID)
+		return true
+	}
+
+	guildQueue.Played(*saved.Track)
+	slog.Info("Restored player state", "guildID", guildID, "track", saved.Track.Info.Title, "position", saved.Position, "queueSize", len(saved.Queue))
+	return true
+}
@@ -0,0 +1,591 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unccord-bot-go/i18n"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	// maxPrefixLength is the longest prefix a guild can choose for its text commands, in characters.
+	maxPrefixLength = 5
+	// helpCommand lists the text commands. It only exists as a text command, slash commands show up in
+	// Discord's command picker instead.
+	helpCommand = "help"
+)
+
+// prefixCommands returns the commands every deployment has, regardless of the enabled modules.
+func (h *Handler) prefixCommands() []Command {
+	return []Command{
+		{
+			Name:        "prefix",
+			Description: "Set up text commands like !skip in this server",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionSubCommand{
+					Name:        "set",
+					Description: "Turn on text commands with a prefix",
+					Options: []discord.ApplicationCommandOption{
+						discord.ApplicationCommandOptionString{
+							Name:        "prefix",
+							Description: "The characters text commands start with, like !",
+							Required:    true,
+							MinLength:   json.Ptr(1),
+							MaxLength:   json.Ptr(maxPrefixLength),
+						},
+					},
+				},
+				discord.ApplicationCommandOptionSubCommand{
+					Name:        "off",
+					Description: "Turn off text commands",
+				},
+			},
+			Permissions: discord.PermissionManageGuild,
+			GuildOnly:   true,
+			Handler:     h.handlePrefix,
+		},
+	}
+}
+
+func (h *Handler) handlePrefix(ctx context.Context, event *CommandEvent) {
+	data := event.SlashCommandInteractionData()
+	if data.SubCommandName == nil {
+		return
+	}
+	guildID := *event.GuildID()
+	locale := interactionLocale(event)
+
+	var description string
+	switch *data.SubCommandName {
+	case "set":
+		prefix := data.String("prefix")
+		if prefix == "" || utf8.RuneCountInString(prefix) > maxPrefixLength || strings.ContainsFunc(prefix, unicode.IsSpace) {
+			replyError(event, errInvalidPrefix)
+			return
+		}
+		if err := h.Store.SetPrefix(ctx, guildID, prefix); err != nil {
+			replyError(event, fmt.Errorf("error saving prefix of guild %s: %w", guildID, err))
+			return
+		}
+		h.setGuildPrefix(guildID, prefix)
+		description = i18n.Text(locale, "prefix.set", prefix+helpCommand)
+	case "off":
+		if err := h.Store.ClearPrefix(ctx, guildID); err != nil {
+			replyError(event, fmt.Errorf("error clearing prefix of guild %s: %w", guildID, err))
+			return
+		}
+		h.setGuildPrefix(guildID, "")
+		description = i18n.Text(locale, "prefix.cleared")
+	default:
+		return
+	}
+
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(discord.NewEmbedBuilder().
+			SetDescription(description).
+			SetColor(ColorSuccess).
+			Build()).
+		Build()); err != nil {
+		slog.Error("Failed to send prefix confirmation", slog.Any("err", err))
+	}
+}
+
+// guildPrefix returns the prefix of a guild's text commands, or an empty string if they are off. Prefixes
+// are loaded once per guild; one that fails to load counts as off until the next message.
+func (h *Handler) guildPrefix(ctx context.Context, guildID snowflake.ID) string {
+	h.mu.Lock()
+	prefix, ok := h.prefixes[guildID]
+	h.mu.Unlock()
+	if ok {
+		return prefix
+	}
+
+	prefix, err := h.Store.Prefix(ctx, guildID)
+	if err != nil {
+		slog.Error("Failed to load command prefix", slog.Any("err", err), "guildID", guildID)
+		return ""
+	}
+	h.setGuildPrefix(guildID, prefix)
+	return prefix
+}
+
+func (h *Handler) setGuildPrefix(guildID snowflake.ID, prefix string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prefixes[guildID] = prefix
+}
+
+// isPrefixCommand reports whether a message starts with the prefix of its guild's text commands.
+func (h *Handler) isPrefixCommand(ctx context.Context, message discord.Message) bool {
+	if message.GuildID == nil {
+		return false
+	}
+	prefix := h.guildPrefix(ctx, *message.GuildID)
+	return prefix != "" && strings.HasPrefix(message.Content, prefix)
+}
+
+// handlePrefixCommand runs a message starting with the guild's prefix as the slash command it names,
+// through the same middleware. The arguments are parsed according to the command's options. It reports
+// whether the message was a command, so it is not handled as anything else.
+func (h *Handler) handlePrefixCommand(ctx context.Context, event *events.MessageCreate) (handled bool) {
+	var (
+		name  string
+		reply *messageReply
+	)
+	defer func() {
+		if r := recover(); r != nil {
+			if reply == nil {
+				// The message was not known to be a command yet, so there is nobody to answer
+				recoverPanic(event, r)
+				return
+			}
+			recoverPanic(reply, r, "command", name)
+			handled = true
+		}
+	}()
+
+	message := event.Message
+	if message.Author.Bot || message.Member == nil || !h.isPrefixCommand(ctx, message) {
+		return false
+	}
+	prefix := h.guildPrefix(ctx, *message.GuildID)
+	name, args := cutWord(strings.TrimSpace(message.Content[len(prefix):]))
+	name = strings.ToLower(name)
+
+	reply = &messageReply{client: event.Client(), message: message, locale: h.guildLocale(*message.GuildID)}
+
+	if name == helpCommand {
+		// Help does not go through the middleware, so it is rate limited here. It is dropped silently, as repeating
+		// the help is all a reply would do
+		if ok, _ := h.limiter.allow(helpCommand, message.Author.ID, message.GuildID); ok {
+			h.replyHelp(reply, prefix, args)
+		}
+		return true
+	}
+	cmd, ok := h.commands.Command(discord.ApplicationCommandTypeSlash, name)
+	if !ok {
+		return false
+	}
+
+	options, err := h.parseArguments(*message.GuildID, cmd.Options, strings.Fields(args))
+	if err != nil {
+		replyError(reply, errInvalidArguments(strings.Join(commandUsage(prefix, cmd), "\n"), err))
+		return true
+	}
+	commandEvent, err := h.prefixCommandEvent(event, reply, cmd, options)
+	if err != nil {
+		replyError(reply, fmt.Errorf("error building text command %q: %w", cmd.Name, err))
+		return true
+	}
+	h.commands.Handle(ctx, commandEvent)
+	return true
+}
+
+// messageMember returns the author of a guild message as a member. Message members lack their user
+// and guild.
+func messageMember(message discord.Message) discord.Member {
+	member := *message.Member
+	member.User = message.Author
+	member.GuildID = *message.GuildID
+	return member
+}
+
+// cutWord splits s after its first word.
+func cutWord(s string) (word, rest string) {
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+// prefixOption is an option of a text command in the JSON form of interaction options.
+type prefixOption struct {
+	Name    string                               `json:"name"`
+	Type    discord.ApplicationCommandOptionType `json:"type"`
+	Value   any                                  `json:"value,omitempty"`
+	Options []prefixOption                       `json:"options,omitempty"`
+}
+
+// prefixCommandEvent builds the interaction of a text command, as if the author had used the slash
+// command with the given options. Its responses are replies to the message.
+func (h *Handler) prefixCommandEvent(event *events.MessageCreate, reply *messageReply, cmd Command, options []prefixOption) (*CommandEvent, error) {
+	message := event.Message
+	// Without the channel in the cache the author's permissions there are unknown, so they get none and
+	// commands that need permissions are refused
+	member, ok := h.messageAuthor(message)
+	if !ok {
+		member = discord.ResolvedMember{Member: messageMember(message)}
+	}
+
+	raw, err := json.Marshal(struct {
+		ID            snowflake.ID                   `json:"id"`
+		Type          discord.InteractionType        `json:"type"`
+		ApplicationID snowflake.ID                   `json:"application_id"`
+		Version       int                            `json:"version"`
+		GuildID       snowflake.ID                   `json:"guild_id"`
+		ChannelID     snowflake.ID                   `json:"channel_id"`
+		Locale        discord.Locale                 `json:"locale"`
+		GuildLocale   discord.Locale                 `json:"guild_locale"`
+		Member        discord.ResolvedMember         `json:"member"`
+		Context       discord.InteractionContextType `json:"context"`
+		Data          any                            `json:"data"`
+	}{
+		ID:            message.ID,
+		Type:          discord.InteractionTypeApplicationCommand,
+		ApplicationID: h.Client.ApplicationID(),
+		Version:       1,
+		GuildID:       *message.GuildID,
+		ChannelID:     message.ChannelID,
+		Locale:        reply.locale,
+		GuildLocale:   reply.locale,
+		Member:        member,
+		Context:       discord.InteractionContextTypeGuild,
+		Data: map[string]any{
+			"name":    cmd.Name,
+			"type":    discord.ApplicationCommandTypeSlash,
+			"options": options,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var interaction discord.ApplicationCommandInteraction
+	if err = json.Unmarshal(raw, &interaction); err != nil {
+		return nil, err
+	}
+
+	return &CommandEvent{
+		ApplicationCommandInteractionCreate: &events.ApplicationCommandInteractionCreate{
+			GenericEvent:                  event.GenericEvent,
+			ApplicationCommandInteraction: interaction,
+			Respond:                       reply.respond,
+		},
+		reply: reply,
+	}, nil
+}
+
+// parseArguments turns the arguments of a text command into the command's options. Arguments are taken
+// in the order the options are declared; subcommands come first, by name. The last option takes the
+// rest of the line if it is a string, so it may contain spaces.
+func (h *Handler) parseArguments(guildID snowflake.ID, options []discord.ApplicationCommandOption, args []string) ([]prefixOption, error) {
+	if len(options) > 0 && isSubCommand(options[0]) {
+		if len(args) == 0 {
+			return nil, errors.New("missing subcommand")
+		}
+		option, ok := findOption(options, strings.ToLower(args[0]))
+		if !ok {
+			return nil, fmt.Errorf("unknown subcommand %q", args[0])
+		}
+		var subOptions []discord.ApplicationCommandOption
+		switch option := option.(type) {
+		case discord.ApplicationCommandOptionSubCommandGroup:
+			for _, subCommand := range option.Options {
+				subOptions = append(subOptions, subCommand)
+			}
+		case discord.ApplicationCommandOptionSubCommand:
+			subOptions = option.Options
+		}
+		parsed, err := h.parseArguments(guildID, subOptions, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		return []prefixOption{{Name: option.OptionName(), Type: option.Type(), Options: parsed}}, nil
+	}
+
+	var parsed []prefixOption
+	for i, option := range options {
+		if len(args) == 0 {
+			if optionRequired(option) {
+				return nil, fmt.Errorf("missing argument %q", option.OptionName())
+			}
+			continue
+		}
+		arg := args[0]
+		args = args[1:]
+		if option.Type() == discord.ApplicationCommandOptionTypeString && i == len(options)-1 {
+			arg = strings.Join(append([]string{arg}, args...), " ")
+			args = nil
+		}
+		value, err := h.parseArgument(guildID, option, arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", option.OptionName(), err)
+		}
+		parsed = append(parsed, prefixOption{Name: option.OptionName(), Type: option.Type(), Value: value})
+	}
+	if len(args) > 0 {
+		return nil, fmt.Errorf("%d arguments too many", len(args))
+	}
+	return parsed, nil
+}
+
+// parseArgument converts a single argument to the value of option, checking the option's constraints.
+// Users, roles and channels are given as mentions or IDs.
+func (h *Handler) parseArgument(guildID snowflake.ID, option discord.ApplicationCommandOption, arg string) (any, error) {
+	switch option := option.(type) {
+	case discord.ApplicationCommandOptionString:
+		length := utf8.RuneCountInString(arg)
+		if (option.MinLength != nil && length < *option.MinLength) || (option.MaxLength != nil && length > *option.MaxLength) {
+			return nil, fmt.Errorf("length %d out of range", length)
+		}
+		return arg, nil
+	case discord.ApplicationCommandOptionInt:
+		value, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		if (option.MinValue != nil && value < *option.MinValue) || (option.MaxValue != nil && value > *option.MaxValue) {
+			return nil, fmt.Errorf("%d out of range", value)
+		}
+		return value, nil
+	case discord.ApplicationCommandOptionFloat:
+		value, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, err
+		}
+		if (option.MinValue != nil && value < *option.MinValue) || (option.MaxValue != nil && value > *option.MaxValue) {
+			return nil, fmt.Errorf("%g out of range", value)
+		}
+		return value, nil
+	case discord.ApplicationCommandOptionBool:
+		return strconv.ParseBool(arg)
+	case discord.ApplicationCommandOptionUser:
+		return parseMention(arg, "<@!", "<@")
+	case discord.ApplicationCommandOptionRole:
+		return parseMention(arg, "<@&")
+	case discord.ApplicationCommandOptionChannel:
+		channelID, err := parseMention(arg, "<#")
+		if err != nil {
+			return nil, err
+		}
+		// Only cached channels can be checked; the command fails later for others of the wrong kind
+		if channel, ok := h.Client.Caches().Channel(channelID); ok {
+			if channel.GuildID() != guildID {
+				return nil, fmt.Errorf("channel %s is in another guild", channelID)
+			}
+			if len(option.ChannelTypes) > 0 && !slices.Contains(option.ChannelTypes, channel.Type()) {
+				return nil, fmt.Errorf("channel %s has type %d", channelID, channel.Type())
+			}
+		}
+		return channelID, nil
+	default:
+		return nil, fmt.Errorf("options of type %d are not supported", option.Type())
+	}
+}
+
+// parseMention parses an ID, bare or in a mention starting with one of the given prefixes.
+func parseMention(arg string, prefixes ...string) (snowflake.ID, error) {
+	if strings.HasSuffix(arg, ">") {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(arg, prefix) {
+				arg = arg[len(prefix) : len(arg)-1]
+				break
+			}
+		}
+	}
+	return snowflake.Parse(arg)
+}
+
+func isSubCommand(option discord.ApplicationCommandOption) bool {
+	return option.Type() == discord.ApplicationCommandOptionTypeSubCommand ||
+		option.Type() == discord.ApplicationCommandOptionTypeSubCommandGroup
+}
+
+// optionRequired reports whether option must be given.
+func optionRequired(option discord.ApplicationCommandOption) bool {
+	switch option := option.(type) {
+	case discord.ApplicationCommandOptionString:
+		return option.Required
+	case discord.ApplicationCommandOptionInt:
+		return option.Required
+	case discord.ApplicationCommandOptionFloat:
+		return option.Required
+	case discord.ApplicationCommandOptionBool:
+		return option.Required
+	case discord.ApplicationCommandOptionUser:
+		return option.Required
+	case discord.ApplicationCommandOptionRole:
+		return option.Required
+	case discord.ApplicationCommandOptionChannel:
+		return option.Required
+	default:
+		return false
+	}
+}
+
+// commandUsage returns how to type a command as a text command, one line per subcommand. Required
+// arguments are shown as <name> and optional ones as [name].
+func commandUsage(prefix string, cmd Command) []string {
+	return optionUsage(prefix+cmd.Name, cmd.Options)
+}
+
+func optionUsage(usage string, options []discord.ApplicationCommandOption) []string {
+	if len(options) > 0 && isSubCommand(options[0]) {
+		var lines []string
+		for _, option := range options {
+			switch option := option.(type) {
+			case discord.ApplicationCommandOptionSubCommandGroup:
+				for _, subCommand := range option.Options {
+					lines = append(lines, optionUsage(usage+" "+option.Name+" "+subCommand.Name, subCommand.Options)...)
+				}
+			case discord.ApplicationCommandOptionSubCommand:
+				lines = append(lines, optionUsage(usage+" "+option.Name, option.Options)...)
+			}
+		}
+		return lines
+	}
+
+	for _, option := range options {
+		if optionRequired(option) {
+			usage += " <" + option.OptionName() + ">"
+		} else {
+			usage += " [" + option.OptionName() + "]"
+		}
+	}
+	return []string{usage}
+}
+
+// replyHelp lists the text commands the author may use with their descriptions, or shows how to use
+// the command named in args.
+func (h *Handler) replyHelp(reply *messageReply, prefix, args string) {
+	// Commands that need permissions are left out if the author's permissions in the channel are unknown
+	var permissions discord.Permissions
+	if member, ok := h.messageAuthor(reply.message); ok {
+		permissions = member.Permissions
+	}
+
+	var description strings.Builder
+	name, _ := cutWord(args)
+	if name != "" {
+		cmd, ok := h.commands.Command(discord.ApplicationCommandTypeSlash, strings.ToLower(strings.TrimPrefix(name, prefix)))
+		if !ok {
+			replyError(reply, errUnknownCommand(prefix+helpCommand))
+			return
+		}
+		fmt.Fprintf(&description, "%s\n", localizedDescription(reply.locale, commandLocalizationID(cmd.Name), cmd.Description))
+		for _, line := range commandUsage(prefix, cmd) {
+			fmt.Fprintf(&description, "`%s`\n", line)
+		}
+	} else {
+		for _, cmd := range h.commands.Commands() {
+			if cmd.Type != discord.ApplicationCommandTypeSlash || !permissions.Has(cmd.Permissions) {
+				continue
+			}
+			fmt.Fprintf(&description, "`%s%s` – %s\n", prefix, cmd.Name, localizedDescription(reply.locale, commandLocalizationID(cmd.Name), cmd.Description))
+		}
+	}
+
+	err := reply.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(discord.NewEmbedBuilder().
+			SetTitle(i18n.Text(reply.locale, "help.title")).
+			SetDescription(description.String()).
+			SetFooterText(i18n.Text(reply.locale, "help.footer", prefix+helpCommand)).
+			SetColor(ColorInfo).
+			Build()).
+		Build())
+	if err != nil {
+		slog.Error("Failed to send help", slog.Any("err", err))
+	}
+}
+
+// localizedDescription returns the translation of a command's description in locale, or the English
+// description it was declared with.
+func localizedDescription(locale discord.Locale, id, description string) string {
+	if text, ok := i18n.Localizations(id + ".description")[locale]; ok {
+		return text
+	}
+	return description
+}
+
+// messageReply answers a text command with replies to its message, standing in for the responses of
+// the interaction the command was turned into. Ephemeral responses are sent to the channel like any
+// other, as messages cannot be shown to a single user.
+type messageReply struct {
+	client  bot.Client
+	message discord.Message
+	locale  discord.Locale // Guild locale, as the replies are seen by everyone in the channel
+
+	mu         sync.Mutex
+	responseID snowflake.ID // First reply, which stands in for the original interaction response
+}
+
+func (r *messageReply) Locale() discord.Locale {
+	return r.locale
+}
+
+func (r *messageReply) GuildLocale() *discord.Locale {
+	return &r.locale
+}
+
+// respond handles the interaction responses of a text command: messages are sent as replies and deferring
+// shows that the bot is typing.
+func (r *messageReply) respond(responseType discord.InteractionResponseType, data discord.InteractionResponseData, opts ...rest.RequestOpt) error {
+	switch responseType {
+	case discord.InteractionResponseTypeCreateMessage:
+		message, ok := data.(discord.MessageCreate)
+		if !ok {
+			return fmt.Errorf("unexpected message of type %T", data)
+		}
+		return r.CreateMessage(message, opts...)
+	case discord.InteractionResponseTypeDeferredCreateMessage:
+		return r.client.Rest().SendTyping(r.message.ChannelID, opts...)
+	default:
+		return fmt.Errorf("response type %d is not supported for text commands", responseType)
+	}
+}
+
+// CreateMessage replies to the command's message without pinging its author.
+func (r *messageReply) CreateMessage(message discord.MessageCreate, opts ...rest.RequestOpt) error {
+	message.Flags = message.Flags.Remove(discord.MessageFlagEphemeral)
+	message.MessageReference = &discord.MessageReference{MessageID: &r.message.ID, ChannelID: &r.message.ChannelID}
+	message.AllowedMentions = &discord.AllowedMentions{}
+
+	sent, err := r.client.Rest().CreateMessage(r.message.ChannelID, message, opts...)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.responseID == 0 {
+		r.responseID = sent.ID
+	}
+	return nil
+}
+
+// edit replaces the first reply, sending it if there is none yet, like editing a deferred response.
+func (r *messageReply) edit(message discord.MessageUpdate, opts ...rest.RequestOpt) error {
+	r.mu.Lock()
+	responseID := r.responseID
+	r.mu.Unlock()
+	if responseID != 0 {
+		_, err := r.client.Rest().UpdateMessage(r.message.ChannelID, responseID, message, opts...)
+		return err
+	}
+
+	var create discord.MessageCreate
+	if message.Content != nil {
+		create.Content = *message.Content
+	}
+	if message.Embeds != nil {
+		create.Embeds = *message.Embeds
+	}
+	if message.Components != nil {
+		create.Components = *message.Components
+	}
+	create.Files = message.Files
+	return r.CreateMessage(create, opts...)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+	"unccord-bot-go/config"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Keys under which uses that are not slash commands are rate limited.
+const (
+	rateLimitPlayLink = "play"  // Links posted in chat, sharing the limits of /play
+	rateLimitPanel    = "panel" // Control panel buttons
+)
+
+// rateLimitPruneInterval is how often buckets that refilled completely are dropped.
+const rateLimitPruneInterval = 10 * time.Minute
+
+// rateLimiter keeps a token bucket per command and user and per command and guild. The limits are read
+// from the configuration on every use, so reloaded limits apply immediately.
+//
+// A bucket is stored as the time at which it will be full again. Each use moves that time one interval
+// further into the future, and a use is allowed while the time is less than burst intervals away. This
+// behaves like counting tokens but needs no refill bookkeeping, and buckets that are full can be dropped.
+type rateLimiter struct {
+	mu        sync.Mutex
+	fullAt    map[rateLimitKey]time.Time
+	lastPrune time.Time
+}
+
+type rateLimitKey struct {
+	command string
+	guild   bool // Whether id is a guild ID rather than a user ID
+	id      snowflake.ID
+}
+
+// bucketUse is a bucket a use takes a token from, with the limits that apply to it.
+type bucketUse struct {
+	key    rateLimitKey
+	bucket config.Bucket
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{fullAt: make(map[rateLimitKey]time.Time), lastPrune: time.Now()}
+}
+
+// allow takes a token for command from the buckets of the user and, if guildID is not nil, the guild.
+// If either bucket is empty no token is taken and allow returns how long to wait before trying again.
+func (l *rateLimiter) allow(command string, userID snowflake.ID, guildID *snowflake.ID) (bool, time.Duration) {
+	limit := config.Get().RateLimits.RateLimit(command)
+	uses := []bucketUse{{rateLimitKey{command: command, id: userID}, limit.User}}
+	if guildID != nil {
+		uses = append(uses, bucketUse{rateLimitKey{command: command, guild: true, id: *guildID}, limit.Guild})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.prune(now)
+
+	var wait time.Duration
+	for _, use := range uses {
+		if unlimited(use.bucket) {
+			continue
+		}
+		fullAt := l.fullAtFrom(use.key, now)
+		// The bucket is empty once taking another token would put it more than burst intervals from full
+		if remaining := fullAt.Add(use.bucket.Interval).Sub(now) - time.Duration(use.bucket.Burst)*use.bucket.Interval; remaining > 0 {
+			wait = max(wait, remaining)
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+
+	for _, use := range uses {
+		if unlimited(use.bucket) {
+			continue
+		}
+		l.fullAt[use.key] = l.fullAtFrom(use.key, now).Add(use.bucket.Interval)
+	}
+	return true, 0
+}
+
+// fullAtFrom returns when the bucket of key is full, or now if it already is.
+func (l *rateLimiter) fullAtFrom(key rateLimitKey, now time.Time) time.Time {
+	if fullAt := l.fullAt[key]; fullAt.After(now) {
+		return fullAt
+	}
+	return now
+}
+
+// prune drops the buckets that are full again, at most once per rateLimitPruneInterval.
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, fullAt := range l.fullAt {
+		if !fullAt.After(now) {
+			delete(l.fullAt, key)
+		}
+	}
+}
+
+func unlimited(bucket config.Bucket) bool {
+	return bucket.Burst <= 0 || bucket.Interval <= 0
+}
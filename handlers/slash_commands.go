@@ -4,348 +4,335 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"unccord-bot-go/i18n"
 
-	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/disgolink/v3/lavalink"
-	"github.com/disgoorg/snowflake/v2"
+	"github.com/disgoorg/json"
 )
 
-var commands = []discord.ApplicationCommandCreate{
-	discord.SlashCommandCreate{
-		Name:        "nowplaying",
-		Description: "Show the currently playing song",
-	},
-	discord.SlashCommandCreate{
-		Name:        "queue",
-		Description: "Show the current music queue",
-	},
-	discord.SlashCommandCreate{
-		Name:        "player",
-		Description: "Control the music player",
-	},
-	discord.SlashCommandCreate{
-		Name:        "skip",
-		Description: "Skip the currently playing song",
-	},
-	discord.SlashCommandCreate{
-		Name:        "pause",
-		Description: "Pause the music player",
-	},
-	discord.SlashCommandCreate{
-		Name:        "resume",
-		Description: "Resume the music player",
-	},
-	discord.SlashCommandCreate{
-		Name:        "leave",
-		Description: "Leave the voice channel",
-	},
-	discord.SlashCommandCreate{
-		Name:        "clearqueue",
-		Description: "Clear the music queue",
-	},
-	discord.SlashCommandCreate{
-		Name:        "shuffle",
-		Description: "Shuffle the music queue",
-	},
-}
-
-func (h *Handler) HandleSlashCommand(event *events.ApplicationCommandInteractionCreate) {
-	switch event.Data.CommandName() {
-	case "nowplaying":
-		h.handleNowPlaying(event)
-	case "queue":
-		h.handleQueue(event)
-	case "player":
-		h.handlePlayer(event)
-	case "skip":
-		h.handleSkip(event)
-	case "pause":
-		h.handlePause(event)
-	case "resume":
-		h.handleResume(event)
-	case "leave":
-		h.handleLeave(event)
-	case "clearqueue":
-		h.handleClearQueue(event)
-	case "shuffle":
-		h.handleShuffle(event)
+// musicCommands returns the slash commands of the music module.
+func (h *Handler) musicCommands() []Command {
+	return []Command{
+		{
+			Name:        "play",
+			Description: "Play a link in your voice channel",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{
+					Name:        "link",
+					Description: "Link to a track, playlist or audio file",
+					Required:    true,
+				},
+			},
+			GuildOnly: true,
+			Music:     true,
+			Handler:   h.handlePlay,
+		},
+		{
+			Name:        "nowplaying",
+			Description: "Show the currently playing song",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handleNowPlaying,
+		},
+		{
+			Name:        "queue",
+			Description: "Show the current music queue",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handleQueue,
+		},
+		{
+			Name:        "player",
+			Description: "Control the music player",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handlePlayer,
+		},
+		{
+			Name:        "skip",
+			Description: "Skip the currently playing song",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionInt{
+					Name:        "amount",
+					Description: "How many tracks to skip",
+					MinValue:    json.Ptr(1),
+				},
+			},
+			GuildOnly: true,
+			Music:     true,
+			Handler:   h.handleSkip,
+		},
+		{
+			Name:        "pause",
+			Description: "Pause the music player",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handlePause,
+		},
+		{
+			Name:        "resume",
+			Description: "Resume the music player",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handleResume,
+		},
+		{
+			Name:        "leave",
+			Description: "Leave the voice channel",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handleLeave,
+		},
+		{
+			Name:        "clearqueue",
+			Description: "Clear the music queue",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handleClearQueue,
+		},
+		{
+			Name:        "shuffle",
+			Description: "Shuffle the music queue",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handleShuffle,
+		},
+		{
+			Name:        "favourites",
+			Description: "Show the tracks you saved from the player",
+			Handler:     h.handleFavourites,
+		},
+		{
+			Name:        "autoplay",
+			Description: "Toggle playing related tracks when the queue runs out",
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handleAutoplay,
+		},
+		{
+			Name:        "nodes",
+			Description: "Show the status of the Lavalink nodes",
+			Permissions: discord.PermissionAdministrator,
+			GuildOnly:   true,
+			Handler:     h.handleNodes,
+		},
+		{
+			Name:        "247",
+			Description: "Keep the bot in a voice channel around the clock",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionSubCommand{
+					Name:        "on",
+					Description: "Enable 24/7 mode",
+					Options: []discord.ApplicationCommandOption{
+						discord.ApplicationCommandOptionChannel{
+							Name:         "channel",
+							Description:  "The voice channel to stay in",
+							Required:     true,
+							ChannelTypes: []discord.ChannelType{discord.ChannelTypeGuildVoice, discord.ChannelTypeGuildStageVoice},
+						},
+						discord.ApplicationCommandOptionString{
+							Name:        "playlist",
+							Description: "Playlist URL used to refill the queue when it runs empty",
+						},
+					},
+				},
+				discord.ApplicationCommandOptionSubCommand{
+					Name:        "off",
+					Description: "Disable 24/7 mode",
+				},
+			},
+			Permissions: discord.PermissionManageGuild,
+			GuildOnly:   true,
+			Music:       true,
+			Handler:     h.handleAlwaysOn,
+		},
 	}
 }
 
-func (h *Handler) RegisterCommands(client bot.Client) error {
-	_, err := client.Rest().SetGlobalCommands(client.ApplicationID(), commands)
-	return err
+// HandleSlashCommand runs a slash command of the enabled modules.
+func (h *Handler) HandleSlashCommand(ctx context.Context, event *events.ApplicationCommandInteractionCreate) {
+	h.commands.Handle(ctx, &CommandEvent{ApplicationCommandInteractionCreate: event})
 }
 
-func (h *Handler) RegisterGuildCommands(client bot.Client, guildID snowflake.ID) error {
-	_, err := client.Rest().SetGuildCommands(client.ApplicationID(), guildID, commands)
-	if err != nil {
-		slog.Error("Failed to register guild commands", slog.Any("err", err), slog.Any("guildID", guildID))
-		return err
+func (h *Handler) handlePlay(ctx context.Context, event *CommandEvent) {
+	links := linkPattern.FindAllString(event.SlashCommandInteractionData().String("link"), -1)
+	if len(links) == 0 {
+		replyError(event, errNotALink)
+		return
 	}
-
-	slog.Info("Successfully registered guild commands", slog.Any("guildID", guildID))
-	return nil
+	h.playLinks(ctx, event, links)
 }
 
-func (h *Handler) handleNowPlaying(event *events.ApplicationCommandInteractionCreate) {
-    player := h.Lavalink.ExistingPlayer(*event.GuildID())
-    if player == nil {
-        event.CreateMessage(discord.NewMessageCreateBuilder().
-            SetContent("No active player found.").
-            SetEphemeral(true).
-            Build())
-        return
-    }
-
-    currentTrack := player.Track()
-    if currentTrack == nil {
-        event.CreateMessage(discord.NewMessageCreateBuilder().
-            SetContent("No song is currently playing.").
-            SetEphemeral(true).
-            Build())
-        return
-    }
-
-    event.CreateMessage(discord.NewMessageCreateBuilder().
-        SetEmbeds(discord.NewEmbedBuilder().
-            SetTitle("Now Playing").
-            SetDescription(fmt.Sprintf("**%s** by **%s**", currentTrack.Info.Title, currentTrack.Info.Author)).
-            SetColor(ColorSuccess).
-            SetThumbnail(*currentTrack.Info.ArtworkURL).
-            Build()).
-        SetEphemeral(true).
-        Build())
-}
+func (h *Handler) handleNowPlaying(ctx context.Context, event *CommandEvent) {
+	player := h.Lavalink.ExistingPlayer(*event.GuildID())
+	if player == nil {
+		replyError(event, errNoPlayer)
+		return
+	}
 
-func (h *Handler) handleQueue(event *events.ApplicationCommandInteractionCreate) {
-	queue := h.Queues.Get(*event.GuildID())
-	if len(queue.Tracks) == 0 {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetContent("The queue is currently empty.").
-			SetEphemeral(true).
-			Build())
+	currentTrack := player.Track()
+	if currentTrack == nil {
+		replyError(event, errNothingPlaying)
 		return
 	}
 
-	var queueList string
-	for i, track := range queue.Tracks[1:] {
-		queueList += fmt.Sprintf("%d. %s by %s\n", i+1, track.Info.Title, track.Info.Author)
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(nowPlayingEmbed(interactionLocale(event), *currentTrack).Build()).
+		SetEphemeral(true).
+		Build()); err != nil {
+		slog.Error("Failed to send now playing", slog.Any("err", err))
 	}
+}
 
-	event.CreateMessage(discord.NewMessageCreateBuilder().
-		SetContent("").
-		SetEmbeds(discord.NewEmbedBuilder().
-			SetTitle("Music Queue").
-			SetDescription(queueList).
-			Build()).
+// nowPlayingEmbed describes the track that is playing.
+func nowPlayingEmbed(locale discord.Locale, track lavalink.Track) *discord.EmbedBuilder {
+	return trackEmbed(track).
+		SetTitle(i18n.Text(locale, "music.now_playing.title")).
+		SetDescription(i18n.Text(locale, "music.now_playing", trackLabel(locale, track), track.Info.Author)).
+		SetColor(ColorSuccess)
+}
+
+func (h *Handler) handleQueue(ctx context.Context, event *CommandEvent) {
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetEmbeds(h.queueEmbed(interactionLocale(event), *event.GuildID()).Build()).
 		SetEphemeral(true).
-		Build())
+		Build()); err != nil {
+		slog.Error("Failed to send queue", slog.Any("err", err))
+	}
 }
 
-func (h *Handler) handlePlayer(event *events.ApplicationCommandInteractionCreate) {
-    player := h.Lavalink.ExistingPlayer(*event.GuildID())
-    if player == nil {
-        event.CreateMessage(discord.NewMessageCreateBuilder().
-            SetContent("No active player found.").
-            SetEphemeral(true).
-            Build())
-        return
-    }
-
-    h.createControlPanel(event.ChannelID(), *event.GuildID())
-    
-    event.CreateMessage(discord.NewMessageCreateBuilder().
-        SetContent("Player controls have been created.").
-        SetEphemeral(true).
-        Build())
+func (h *Handler) handlePlayer(ctx context.Context, event *CommandEvent) {
+	player := h.Lavalink.ExistingPlayer(*event.GuildID())
+	if player == nil {
+		replyError(event, errNoPlayer)
+		return
+	}
+
+	response := deferResponse(ctx, event, true)
+	h.createControlPanel(ctx, event.ChannelID(), *event.GuildID())
+
+	response.Edit(discord.NewMessageUpdateBuilder().
+		SetContent(i18n.Text(interactionLocale(event), "music.player_created")).
+		Build())
 }
 
-func (h *Handler) handleSkip(event *events.ApplicationCommandInteractionCreate) {
+func (h *Handler) handleSkip(ctx context.Context, event *CommandEvent) {
 	amount := 1
 	if data, ok := event.SlashCommandInteractionData().OptInt("amount"); ok {
 		amount = data
 	}
 
-	embed, err := h.skipTracks(*event.GuildID(), amount)
+	response := deferResponse(ctx, event, false)
+	embed, err := h.skipTracks(ctx, interactionLocale(event), *event.GuildID(), amount)
 	if err != nil {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription(fmt.Sprintf("Error: %s", err)).
-				SetColor(ColorError).
-				Build()).
-			Build())
+		response.EditError(err)
 		return
 	}
 
-	event.CreateMessage(discord.NewMessageCreateBuilder().
-		SetEmbeds(embed.Build()).
-		Build())
+	response.EditEmbed(embed.Build())
 }
 
-func (h *Handler) handlePause(event *events.ApplicationCommandInteractionCreate) {
-	player := h.Lavalink.Player(*event.GuildID())
+func (h *Handler) handlePause(ctx context.Context, event *CommandEvent) {
+	player := h.Lavalink.ExistingPlayer(*event.GuildID())
 	if player == nil {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription("No music player found for this guild.").
-				SetColor(ColorWarning).
-				Build()).
-			Build())
+		replyError(event, errNoPlayer)
 		return
 	}
 
-	if err := player.Update(context.TODO(), lavalink.WithPaused(true)); err != nil {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription(fmt.Sprintf("Error: %s", err)).
-				SetColor(ColorError).
-				Build()).
-			Build())
+	response := deferResponse(ctx, event, false)
+	if err := player.Update(ctx, lavalink.WithPaused(true)); err != nil {
+		response.EditError(fmt.Errorf("error pausing player: %w", err))
 		return
 	}
 
-	event.CreateMessage(discord.NewMessageCreateBuilder().
-		SetEmbeds(discord.NewEmbedBuilder().
-			SetDescription("Music paused.").
-			SetColor(ColorSuccess).
-			Build()).
+	response.EditEmbed(discord.NewEmbedBuilder().
+		SetDescription(i18n.Text(interactionLocale(event), "music.paused")).
+		SetColor(ColorSuccess).
 		Build())
 }
 
-func (h *Handler) handleResume(event *events.ApplicationCommandInteractionCreate) {
-	player := h.Lavalink.Player(*event.GuildID())
+func (h *Handler) handleResume(ctx context.Context, event *CommandEvent) {
+	player := h.Lavalink.ExistingPlayer(*event.GuildID())
 	if player == nil {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription("No music player found for this guild.").
-				SetColor(ColorWarning).
-				Build()).
-			Build())
+		replyError(event, errNoPlayer)
 		return
 	}
 
-	if err := player.Update(context.TODO(), lavalink.WithPaused(false)); err != nil {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription(fmt.Sprintf("Error: %s", err)).
-				SetColor(ColorError).
-				Build()).
-			Build())
+	response := deferResponse(ctx, event, false)
+	if err := player.Update(ctx, lavalink.WithPaused(false)); err != nil {
+		response.EditError(fmt.Errorf("error resuming player: %w", err))
 		return
 	}
 
-	event.CreateMessage(discord.NewMessageCreateBuilder().
-		SetEmbeds(discord.NewEmbedBuilder().
-			SetDescription("Music resumed.").
-			SetColor(ColorSuccess).
-			Build()).
+	response.EditEmbed(discord.NewEmbedBuilder().
+		SetDescription(i18n.Text(interactionLocale(event), "music.resumed")).
+		SetColor(ColorSuccess).
 		Build())
 }
 
-func (h *Handler) handleClearQueue(event *events.ApplicationCommandInteractionCreate) {
+func (h *Handler) handleClearQueue(ctx context.Context, event *CommandEvent) {
 	guildID := *event.GuildID()
 	queue := h.Queues.Get(guildID)
-	player := h.Lavalink.Player(guildID)
-
-	if player == nil || len(queue.Tracks) == 0 {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription("There are no tracks in the queue to clear.").
-				SetColor(ColorWarning).
-				Build()).
-			SetEphemeral(true).
-			Build())
-		return
-	}
+	player := h.Lavalink.ExistingPlayer(guildID)
 
-	currentTrack := player.Track()
-	initialQueueSize := len(queue.Tracks)
-
-	if currentTrack != nil {
-		// Keep the currently playing track
-		queue.Tracks = []lavalink.Track{*currentTrack}
-	} else {
-		// Clear all tracks if nothing is currently playing
-		queue.Tracks = []lavalink.Track{}
+	if player == nil || queue.Len() == 0 {
+		replyError(event, errQueueEmpty)
+		return
 	}
 
-	clearedTracks := initialQueueSize - len(queue.Tracks)
+	// The currently playing track is not part of the queue, so it keeps playing
+	clearedTracks := queue.Clear()
 
-	event.CreateMessage(discord.NewMessageCreateBuilder().
+	locale := interactionLocale(event)
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
 		SetEmbeds(discord.NewEmbedBuilder().
-			SetTitle("Queue Cleared").
-			SetDescription(fmt.Sprintf("Cleared %d tracks from the queue.", clearedTracks)).
+			SetTitle(i18n.Text(locale, "music.queue_cleared.title")).
+			SetDescription(i18n.Count(locale, "music.queue_cleared", clearedTracks)).
 			SetColor(ColorSuccess).
 			Build()).
-		Build())
+		Build()); err != nil {
+		slog.Error("Failed to send cleared queue", slog.Any("err", err))
+	}
 }
 
-func (h *Handler) handleShuffle(event *events.ApplicationCommandInteractionCreate) {
-	queue := h.Queues.Get(*event.GuildID())
-	if len(queue.Tracks) <= 1 {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription("The queue is currently empty.").
-				SetColor(ColorWarning).
-				Build()).
-			SetEphemeral(true).
-			Build())
+func (h *Handler) handleShuffle(ctx context.Context, event *CommandEvent) {
+	if !h.Queues.Get(*event.GuildID()).Shuffle() {
+		replyError(event, errNotEnoughToShuffle)
 		return
 	}
 
-	queue.Shuffle()
-
-	event.CreateMessage(discord.NewMessageCreateBuilder().
+	if err := event.CreateMessage(discord.NewMessageCreateBuilder().
 		SetEmbeds(discord.NewEmbedBuilder().
-			SetDescription("The queue has been shuffled.").
+			SetDescription(i18n.Text(interactionLocale(event), "music.shuffled")).
 			SetColor(ColorSuccess).
 			Build()).
-		Build())
+		Build()); err != nil {
+		slog.Error("Failed to send shuffle confirmation", slog.Any("err", err))
+	}
 }
 
-func (h *Handler) handleLeave(event *events.ApplicationCommandInteractionCreate) {
-	player := h.Lavalink.Player(*event.GuildID())
+func (h *Handler) handleLeave(ctx context.Context, event *CommandEvent) {
+	player := h.Lavalink.ExistingPlayer(*event.GuildID())
 	if player == nil {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription("No music player found for this guild.").
-				SetColor(ColorWarning).
-				Build()).
-			Build())
+		replyError(event, errNoPlayer)
 		return
 	}
 
-	if err := player.Destroy(context.TODO()); err != nil {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription(fmt.Sprintf("Error: %s", err)).
-				SetColor(ColorError).
-				Build()).
-			Build())
+	if h.isAlwaysOn(*event.GuildID()) {
+		replyError(event, errAlwaysOnLeave)
 		return
 	}
 
-	// Clear the queue
-	h.Queues.Get(*event.GuildID()).Tracks = nil
-
-	// Disconnect bot from voice channel
-	if err := h.Client.UpdateVoiceState(context.TODO(), *event.GuildID(), nil, false, false); err != nil {
-		event.CreateMessage(discord.NewMessageCreateBuilder().
-			SetEmbeds(discord.NewEmbedBuilder().
-				SetDescription(fmt.Sprintf("Error while disconnecting: `%s`", err)).
-				SetColor(ColorError).
-				Build()).
-			Build())
+	response := deferResponse(ctx, event, false)
+	if err := h.disconnect(ctx, *event.GuildID()); err != nil {
+		response.EditError(fmt.Errorf("error disconnecting: %w", err))
 		return
 	}
 
-	event.CreateMessage(discord.NewMessageCreateBuilder().
-		SetEmbeds(discord.NewEmbedBuilder().
-			SetDescription("Left the voice channel and cleared the queue.").
-			SetColor(ColorSuccess).
-			Build()).
+	response.EditEmbed(discord.NewEmbedBuilder().
+		SetDescription(i18n.Text(interactionLocale(event), "music.left")).
+		SetColor(ColorSuccess).
 		Build())
-}
\ No newline at end of file
+}
@@ -1,80 +1,68 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"unccord-bot-go/config"
+	"unccord-bot-go/i18n"
+	"unccord-bot-go/storage"
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
 	"github.com/disgoorg/snowflake/v2"
 )
 
-// InsertStarredMessage handles the insertion of a new starred message into the PostgreSQL database.
-func InsertStarredMessage(messageID, channelID, authorID, content string) error {
-	query := `INSERT INTO starboard(message_id, channel_id, author_id, content, star_count)
-	VALUES($1, $2, $3, $4, 1)
-	ON CONFLICT(message_id) DO UPDATE SET star_count = starboard.star_count + 1`
-	_, err := config.DB.Exec(query, messageID, channelID, authorID, content)
-	return err
-}
-
-// GetStarredMessage retrieves the number of stars for a given message ID from the PostgreSQL database.
-func GetStarredMessage(messageID string) (int, error) {
-	var starCount int
-	query := `SELECT star_count FROM starboard WHERE message_id = $1`
-	err := config.DB.QueryRow(query, messageID).Scan(&starCount)
-	return starCount, err
-}
-
 // OnReactionAdd handles star reactions and posts the message to the starboard if it reaches the threshold.
-func OnReactionAdd(event *events.GuildMessageReactionAdd) {
+func (h *Handler) OnReactionAdd(ctx context.Context, event *events.GuildMessageReactionAdd) {
 	if !isStarEmoji(event.Emoji) {
 		return
 	}
 
-	message, err := fetchMessage(event.Client(), event.ChannelID, event.MessageID)
+	message, err := fetchMessage(ctx, event.Client(), event.ChannelID, event.MessageID)
 	if err != nil {
 		log.Printf("Error fetching message: %v", err)
 		return
 	}
 
-	if err := updateStarCount(event.MessageID.String(), event.ChannelID.String(), message.Author.ID.String(), message.Content, true); err != nil {
+	starred := storage.StarredMessage{MessageID: event.MessageID, ChannelID: event.ChannelID, AuthorID: message.Author.ID, Content: message.Content}
+	if err := h.Store.AddStar(ctx, starred); err != nil {
 		log.Printf("Error updating star count: %v", err)
 		return
 	}
 
-	starCount, err := GetStarredMessage(event.MessageID.String())
+	starCount, err := h.Store.StarCount(ctx, event.MessageID)
 	if err != nil {
-		handleStarCountError(err, event.MessageID.String())
+		handleStarCountError(err, event.MessageID)
 		return
 	}
 
-	if err := handleStarboardPost(event, message, starCount); err != nil {
+	if err := h.handleStarboardPost(ctx, event, message, starCount); err != nil {
 		log.Printf("Error handling starboard post: %v", err)
 	}
 }
 
 // OnReactionRemove handles the removal of reactions and updates the starboard accordingly.
-func OnReactionRemove(event *events.GuildMessageReactionRemove) {
+func (h *Handler) OnReactionRemove(ctx context.Context, event *events.GuildMessageReactionRemove) {
 	if !isStarEmoji(event.Emoji) {
 		return
 	}
 
-	if err := updateStarCount(event.MessageID.String(), event.ChannelID.String(), "", "", false); err != nil {
+	if err := h.Store.RemoveStar(ctx, event.MessageID); err != nil {
 		log.Printf("Error updating star count: %v", err)
 		return
 	}
 
-	starCount, err := GetStarredMessage(event.MessageID.String())
+	starCount, err := h.Store.StarCount(ctx, event.MessageID)
 	if err != nil {
-		handleStarCountError(err, event.MessageID.String())
+		handleStarCountError(err, event.MessageID)
 		return
 	}
 
-	if err := updateStarboardMessage(event.Client(), event.MessageID.String(), starCount); err != nil {
+	if err := h.updateStarboardMessage(ctx, event.Client(), event.MessageID, starCount); err != nil {
 		log.Printf("Error updating starboard message: %v", err)
 	}
 }
@@ -85,64 +73,48 @@ func isStarEmoji(emoji discord.PartialEmoji) bool {
 	return emoji.Name != nil && *emoji.Name == "⭐"
 }
 
-func fetchMessage(client bot.Client, channelID, messageID snowflake.ID) (*discord.Message, error) {
-	message, err := client.Rest().GetMessage(channelID, messageID)
+func fetchMessage(ctx context.Context, client bot.Client, channelID, messageID snowflake.ID) (*discord.Message, error) {
+	message, err := client.Rest().GetMessage(channelID, messageID, rest.WithCtx(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("error fetching message: %w", err)
 	}
 	return message, nil
 }
 
-func updateStarCount(messageID, channelID, authorID, content string, increment bool) error {
-	var err error
-	if increment {
-		err = InsertStarredMessage(messageID, channelID, authorID, content)
-	} else {
-		err = RemoveStarFromMessage(messageID)
-	}
-	return err
-}
-
-func handleStarCountError(err error, messageID string) {
-	if err == sql.ErrNoRows {
+func handleStarCountError(err error, messageID snowflake.ID) {
+	if errors.Is(err, storage.ErrNotFound) {
 		log.Printf("No stars found for message %s, skipping starboard update", messageID)
 	} else {
 		log.Printf("Error fetching star count: %v", err)
 	}
 }
 
-func handleStarboardPost(event *events.GuildMessageReactionAdd, message *discord.Message, starCount int) error {
-	existingStarboardMessageID, err := GetStarboardMessageID(event.MessageID.String())
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("error checking existing starboard message: %w", err)
+func (h *Handler) handleStarboardPost(ctx context.Context, event *events.GuildMessageReactionAdd, message *discord.Message, starCount int) error {
+	_, err := h.Store.StarboardMessageID(ctx, event.MessageID)
+	if err == nil {
+		return h.updateStarboardMessage(ctx, event.Client(), event.MessageID, starCount)
 	}
-
-	if existingStarboardMessageID != "" {
-		return updateStarboardMessage(event.Client(), event.MessageID.String(), starCount)
+	if !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("error checking existing starboard message: %w", err)
 	}
 
-	if starCount >= config.AppConfig.StarThreshold {
-		return PostToStarboard(event, message, starCount)
+	if starCount >= config.Get().Starboard.Threshold {
+		return h.PostToStarboard(ctx, event.Client(), event.GuildID, message, starCount)
 	}
 
 	return nil
 }
 
-func updateStarboardMessage(client bot.Client, messageID string, starCount int) error {
-	starboardMessageID, err := GetStarboardMessageID(messageID)
+func (h *Handler) updateStarboardMessage(ctx context.Context, client bot.Client, messageID snowflake.ID, starCount int) error {
+	starboardMessageIDSnowflake, err := h.Store.StarboardMessageID(ctx, messageID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, storage.ErrNotFound) {
 			return nil // Message not in starboard yet, nothing to update
 		}
 		return fmt.Errorf("error fetching starboard message ID: %w", err)
 	}
 
-	starboardMessageIDSnowflake, err := snowflake.Parse(starboardMessageID)
-	if err != nil {
-		return fmt.Errorf("error parsing starboard message ID: %w", err)
-	}
-
-	message, err := client.Rest().GetMessage(config.AppConfig.StarboardChannelID, starboardMessageIDSnowflake)
+	message, err := client.Rest().GetMessage(config.Get().Starboard.ChannelID, starboardMessageIDSnowflake, rest.WithCtx(ctx))
 	if err != nil {
 		return fmt.Errorf("error fetching starboard message: %w", err)
 	}
@@ -154,7 +126,7 @@ func updateStarboardMessage(client bot.Client, messageID string, starCount int)
 	updatedEmbed := message.Embeds[0]
 	updatedEmbed.Title = fmt.Sprintf("⭐ %d # %s", starCount, message.ChannelID)
 
-	_, err = client.Rest().UpdateMessage(config.AppConfig.StarboardChannelID, starboardMessageIDSnowflake, discord.NewMessageUpdateBuilder().SetEmbeds(updatedEmbed).Build())
+	_, err = client.Rest().UpdateMessage(config.Get().Starboard.ChannelID, starboardMessageIDSnowflake, discord.NewMessageUpdateBuilder().SetEmbeds(updatedEmbed).Build(), rest.WithCtx(ctx))
 	if err != nil {
 		return fmt.Errorf("error updating starboard message: %w", err)
 	}
@@ -162,20 +134,6 @@ func updateStarboardMessage(client bot.Client, messageID string, starCount int)
 	return nil
 }
 
-// GetStarboardMessageID retrieves the starboard message ID from the database based on the original message ID.
-func GetStarboardMessageID(messageID string) (string, error) {
-	var starboardMessageID sql.NullString
-	query := `SELECT starboard_message_id FROM starboard WHERE message_id = $1`
-	err := config.DB.QueryRow(query, messageID).Scan(&starboardMessageID)
-	if err != nil {
-		return "", err
-	}
-	if !starboardMessageID.Valid {
-		return "", sql.ErrNoRows
-	}
-	return starboardMessageID.String, nil
-}
-
 // DeleteStarboardMessage deletes a message from the starboard channel.
 func DeleteStarboardMessage(client bot.Client, starboardMessageID string) error {
 	// Parse the starboard message ID from string to snowflake.ID
@@ -186,36 +144,15 @@ func DeleteStarboardMessage(client bot.Client, starboardMessageID string) error
 	}
 
 	// Delete the message from the starboard channel
-	err = client.Rest().DeleteMessage(config.AppConfig.StarboardChannelID, starboardMessageIDSnowflake)
+	err = client.Rest().DeleteMessage(config.Get().Starboard.ChannelID, starboardMessageIDSnowflake)
 	if err != nil {
 		log.Printf("Error deleting message from starboard: %v", err)
 	}
 	return err
 }
 
-// RemoveStarFromMessage decreases the star count of a message in the PostgreSQL database.
-func RemoveStarFromMessage(messageID string) error {
-	query := `UPDATE starboard SET star_count = star_count - 1 WHERE message_id = $1 AND star_count > 0`
-	_, err := config.DB.Exec(query, messageID)
-	return err
-}
-
-// RemoveFromStarboard deletes a message from the starboard in the PostgreSQL database.
-func RemoveFromStarboard(messageID string) error {
-	query := `DELETE FROM starboard WHERE message_id = $1`
-	_, err := config.DB.Exec(query, messageID)
-	return err
-}
-
-// UpdateStarboardMessageID updates the starboard message ID in the PostgreSQL database after the message is posted to the starboard.
-func UpdateStarboardMessageID(messageID, starboardMessageID string) error {
-	query := `UPDATE starboard SET starboard_message_id = $1 WHERE message_id = $2`
-	_, err := config.DB.Exec(query, starboardMessageID, messageID)
-	return err
-}
-
 // PostToStarboard posts a message to the starboard and updates the database with the starboard message ID.
-func PostToStarboard(event *events.GuildMessageReactionAdd, message *discord.Message, starCount int) error {
+func (h *Handler) PostToStarboard(ctx context.Context, client bot.Client, guildID snowflake.ID, message *discord.Message, starCount int) error {
 	// Safely handle the author's avatar URL
 	avatarURL := ""
 	if message.Author.AvatarURL() != nil {
@@ -223,20 +160,22 @@ func PostToStarboard(event *events.GuildMessageReactionAdd, message *discord.Mes
 	}
 
 	// Fetch the channel information
-	channel, err := event.Client().Rest().GetChannel(event.ChannelID)
+	channel, err := client.Rest().GetChannel(message.ChannelID, rest.WithCtx(ctx))
 	if err != nil {
 		return fmt.Errorf("error fetching channel information: %w", err)
 	}
 
-	// Create the embed for the starred message
+	// Create the embed for the starred message. The starboard is seen by everyone, so it is in the guild's locale
+	locale := h.guildLocale(guildID)
 	embedBuilder := discord.NewEmbedBuilder().
 		SetTitle(fmt.Sprintf("⭐ %d | #%s", starCount, channel.Name())). // Use channel.Name instead of channel ID
 		SetDescription(message.Content).
-		AddField("Source", fmt.Sprintf("[Jump!](https://discord.com/channels/%s/%s/%s)", event.GuildID.String(), event.ChannelID.String(), event.MessageID.String()), false).
+		AddField(i18n.Text(locale, "starboard.source"), i18n.Text(locale, "starboard.jump", fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, message.ChannelID, message.ID)), false).
 		SetAuthorName(message.Author.Username).
 		SetAuthorIcon(avatarURL).
 		SetTimestamp(message.CreatedAt).
-		SetFooterText("From #" + channel.Name()) // Call the Name() method
+		SetFooterText(i18n.Text(locale, "starboard.from", channel.Name())).
+		SetColor(config.Get().Starboard.Color)
 
 	if len(message.Attachments) > 0 {
 		embedBuilder.SetImage(message.Attachments[0].URL) // Add the first attachment as an image
@@ -245,13 +184,13 @@ func PostToStarboard(event *events.GuildMessageReactionAdd, message *discord.Mes
 	embed := embedBuilder.Build()
 
 	// Send the embed to the starboard channel and capture the message ID
-	starboardMessage, err := event.Client().Rest().CreateMessage(config.AppConfig.StarboardChannelID, discord.NewMessageCreateBuilder().AddEmbeds(embed).Build())
+	starboardMessage, err := client.Rest().CreateMessage(config.Get().Starboard.ChannelID, discord.NewMessageCreateBuilder().AddEmbeds(embed).Build(), rest.WithCtx(ctx))
 	if err != nil {
 		return fmt.Errorf("error sending message to starboard: %w", err)
 	}
 
 	// Update the database with the starboard message ID
-	err = UpdateStarboardMessageID(event.MessageID.String(), starboardMessage.ID.String())
+	err = h.Store.SetStarboardMessageID(ctx, message.ID, starboardMessage.ID)
 	if err != nil {
 		return fmt.Errorf("error updating starboard message ID in database: %w", err)
 	}
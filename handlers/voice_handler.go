@@ -7,14 +7,39 @@ import (
 	"github.com/disgoorg/disgo/events"
 )
 
-func (h *Handler) OnVoiceStateUpdate(event *events.GuildVoiceStateUpdate) {
-	if event.VoiceState.UserID == h.Client.ApplicationID() {
-		slog.Info("Voice state updated", "guildID", event.VoiceState.GuildID, "channelID", event.VoiceState.ChannelID, "sessionID", event.VoiceState.SessionID)
-		h.Lavalink.OnVoiceStateUpdate(context.TODO(), event.VoiceState.GuildID, event.VoiceState.ChannelID, event.VoiceState.SessionID)
+func (h *Handler) OnVoiceStateUpdate(ctx context.Context, event *events.GuildVoiceStateUpdate) {
+	guildID := event.VoiceState.GuildID
+	if event.VoiceState.UserID != h.Client.ApplicationID() {
+		// Someone joined, left or moved; check whether the bot still has listeners
+		h.checkVoiceChannelEmpty(ctx, guildID)
+		return
 	}
+
+	slog.Info("Voice state updated", "guildID", guildID, "channelID", event.VoiceState.ChannelID, "sessionID", event.VoiceState.SessionID)
+	h.Lavalink.OnVoiceStateUpdate(ctx, guildID, event.VoiceState.ChannelID, event.VoiceState.SessionID)
+
+	if event.VoiceState.ChannelID == nil {
+		h.clearVoiceTimers(guildID)
+		if !h.isAlwaysOn(guildID) {
+			// The bot was disconnected, so there is no player to restore after a restart
+			if err := h.Store.DeleteSavedPlayer(ctx, guildID); err != nil {
+				slog.Error("Failed to delete saved player state", slog.Any("err", err), "guildID", guildID)
+			}
+		}
+		h.rejoinAlwaysOn(guildID)
+		return
+	}
+	h.checkVoiceChannelEmpty(ctx, guildID)
 }
 
-func (h *Handler) OnVoiceServerUpdate(event *events.VoiceServerUpdate) {
-	slog.Info("Voice server updated", "guildID", event.GuildID, "endpoint", *event.Endpoint, "token", event.Token)
-	h.Lavalink.OnVoiceServerUpdate(context.TODO(), event.GuildID, event.Token, *event.Endpoint)
+func (h *Handler) OnVoiceServerUpdate(ctx context.Context, event *events.VoiceServerUpdate) {
+	if event.Endpoint == nil {
+		// Discord sends no endpoint while the voice server is unavailable and follows up with a new one
+		slog.Info("Voice server unavailable, waiting for a new one", "guildID", event.GuildID)
+		return
+	}
+
+	slog.Info("Voice server updated", "guildID", event.GuildID, "endpoint", *event.Endpoint)
+	h.setVoiceServer(event.GuildID, voiceServer{Token: event.Token, Endpoint: *event.Endpoint})
+	h.Lavalink.OnVoiceServerUpdate(ctx, event.GuildID, event.Token, *event.Endpoint)
 }
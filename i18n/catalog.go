@@ -0,0 +1,891 @@
+package i18n
+
+import "github.com/disgoorg/disgo/discord"
+
+const (
+	en = Fallback
+	de = discord.LocaleGerman
+	fr = discord.LocaleFrench
+	es = discord.LocaleSpanishES
+)
+
+// catalog maps message IDs to their text per locale. Texts are fmt format strings. Errors shown to users
+// have a ".title" entry next to their message.
+//
+// Commands are declared in English with their definitions, so "command." entries only hold translations.
+// Slash command names are not translated so that the commands mentioned in messages, like `/player`,
+// are the same for everyone; context menu entries are.
+var catalog = map[string]map[discord.Locale]string{
+	// Command definitions
+	"command.play.description": {
+		de: "Spielt einen Link in deinem Sprachkanal ab",
+		fr: "Lit un lien dans ton salon vocal",
+		es: "Reproduce un enlace en tu canal de voz",
+	},
+	"command.play.link.description": {
+		de: "Link zu einem Titel, einer Playlist oder einer Audiodatei",
+		fr: "Lien vers un titre, une playlist ou un fichier audio",
+		es: "Enlace a una pista, una lista o un archivo de audio",
+	},
+	"command.nowplaying.description": {
+		de: "Zeigt den Song, der gerade läuft",
+		fr: "Affiche la chanson en cours de lecture",
+		es: "Muestra la canción que se está reproduciendo",
+	},
+	"command.queue.description": {
+		de: "Zeigt die aktuelle Warteschlange",
+		fr: "Affiche la file d'attente actuelle",
+		es: "Muestra la cola de reproducción actual",
+	},
+	"command.player.description": {
+		de: "Steuert den Musikplayer",
+		fr: "Contrôle le lecteur de musique",
+		es: "Controla el reproductor de música",
+	},
+	"command.skip.description": {
+		de: "Überspringt den Song, der gerade läuft",
+		fr: "Passe la chanson en cours",
+		es: "Salta la canción actual",
+	},
+	"command.skip.amount.description": {
+		de: "Wie viele Titel übersprungen werden",
+		fr: "Nombre de titres à passer",
+		es: "Cuántas pistas saltar",
+	},
+	"command.pause.description": {
+		de: "Pausiert den Musikplayer",
+		fr: "Met le lecteur de musique en pause",
+		es: "Pausa el reproductor de música",
+	},
+	"command.resume.description": {
+		de: "Setzt den Musikplayer fort",
+		fr: "Reprend la lecture",
+		es: "Reanuda el reproductor de música",
+	},
+	"command.leave.description": {
+		de: "Verlässt den Sprachkanal",
+		fr: "Quitte le salon vocal",
+		es: "Sale del canal de voz",
+	},
+	"command.clearqueue.description": {
+		de: "Leert die Warteschlange",
+		fr: "Vide la file d'attente",
+		es: "Vacía la cola de reproducción",
+	},
+	"command.shuffle.description": {
+		de: "Mischt die Warteschlange",
+		fr: "Mélange la file d'attente",
+		es: "Mezcla la cola de reproducción",
+	},
+	"command.favourites.description": {
+		de: "Zeigt die Titel, die du im Player gespeichert hast",
+		fr: "Affiche les titres que tu as enregistrés depuis le lecteur",
+		es: "Muestra las pistas que guardaste desde el reproductor",
+	},
+	"command.autoplay.description": {
+		de: "Spielt ähnliche Titel, wenn die Warteschlange leer ist (an/aus)",
+		fr: "Active ou désactive les titres similaires quand la file est vide",
+		es: "Activa o desactiva pistas similares cuando la cola se vacía",
+	},
+	"command.nodes.description": {
+		de: "Zeigt den Status der Lavalink-Nodes",
+		fr: "Affiche l'état des nœuds Lavalink",
+		es: "Muestra el estado de los nodos de Lavalink",
+	},
+	"command.247.description": {
+		de: "Hält den Bot rund um die Uhr in einem Sprachkanal",
+		fr: "Garde le bot dans un salon vocal en permanence",
+		es: "Mantiene el bot en un canal de voz las 24 horas",
+	},
+	"command.247.on.description": {
+		de: "Aktiviert den 24/7-Modus",
+		fr: "Active le mode 24/7",
+		es: "Activa el modo 24/7",
+	},
+	"command.247.on.channel.description": {
+		de: "Der Sprachkanal, in dem der Bot bleibt",
+		fr: "Le salon vocal où rester",
+		es: "El canal de voz en el que quedarse",
+	},
+	"command.247.on.playlist.description": {
+		de: "Playlist-URL, mit der die leere Warteschlange aufgefüllt wird",
+		fr: "URL de la playlist qui remplit la file quand elle est vide",
+		es: "URL de la lista que rellena la cola cuando se vacía",
+	},
+	"command.247.off.description": {
+		de: "Deaktiviert den 24/7-Modus",
+		fr: "Désactive le mode 24/7",
+		es: "Desactiva el modo 24/7",
+	},
+	"command.prefix.description": {
+		de: "Richtet Textbefehle wie !skip auf diesem Server ein",
+		fr: "Configure les commandes texte comme !skip sur ce serveur",
+		es: "Configura comandos de texto como !skip en este servidor",
+	},
+	"command.prefix.set.description": {
+		de: "Schaltet Textbefehle mit einem Präfix ein",
+		fr: "Active les commandes texte avec un préfixe",
+		es: "Activa los comandos de texto con un prefijo",
+	},
+	"command.prefix.set.prefix.description": {
+		de: "Die Zeichen, mit denen Textbefehle beginnen, z. B. !",
+		fr: "Les caractères qui commencent les commandes texte, comme !",
+		es: "Los caracteres con los que empiezan los comandos de texto, como !",
+	},
+	"command.prefix.off.description": {
+		de: "Schaltet Textbefehle aus",
+		fr: "Désactive les commandes texte",
+		es: "Desactiva los comandos de texto",
+	},
+	"command.play_this_link.name": {
+		de: "Diesen Link abspielen",
+		fr: "Lire ce lien",
+		es: "Reproducir este enlace",
+	},
+	"command.what_is_this_user_listening_to.name": {
+		de: "Was hört diese Person",
+		fr: "Qu'écoute ce membre",
+		es: "Qué escucha este usuario",
+	},
+	"command.force_to_starboard.name": {
+		de: "Aufs Starboard setzen",
+		fr: "Forcer sur le starboard",
+		es: "Forzar al starboard",
+	},
+
+	// Music responses
+	"music.now_playing.title": {
+		en: "Now Playing",
+		de: "Läuft gerade",
+		fr: "En cours de lecture",
+		es: "Reproduciendo ahora",
+	},
+	"music.now_playing": {
+		en: "**%s** by **%s**",
+		de: "**%s** von **%s**",
+		fr: "**%s** par **%s**",
+		es: "**%s** de **%s**",
+	},
+	"music.added.title": {
+		en: "Added to Queue",
+		de: "Zur Warteschlange hinzugefügt",
+		fr: "Ajouté à la file d'attente",
+		es: "Añadido a la cola",
+	},
+	"music.added": {
+		en: "**%s**\n\nPosition in queue: %d",
+		de: "**%s**\n\nPosition in der Warteschlange: %d",
+		fr: "**%s**\n\nPosition dans la file : %d",
+		es: "**%s**\n\nPosición en la cola: %d",
+	},
+	"music.playlist_added.title": {
+		en: "Playlist Added to Queue",
+		de: "Playlist zur Warteschlange hinzugefügt",
+		fr: "Playlist ajoutée à la file d'attente",
+		es: "Lista añadida a la cola",
+	},
+	"music.playlist_added": {
+		en: "Added %d tracks to the queue",
+		de: "%d Titel zur Warteschlange hinzugefügt",
+		fr: "%d titres ajoutés à la file d'attente",
+		es: "Se añadieron %d pistas a la cola",
+	},
+	"music.player_created": {
+		en: "Player controls have been created.",
+		de: "Die Player-Steuerung wurde erstellt.",
+		fr: "Les commandes du lecteur ont été créées.",
+		es: "Se han creado los controles del reproductor.",
+	},
+	"music.paused": {
+		en: "Music paused.",
+		de: "Musik pausiert.",
+		fr: "Musique en pause.",
+		es: "Música en pausa.",
+	},
+	"music.resumed": {
+		en: "Music resumed.",
+		de: "Musik fortgesetzt.",
+		fr: "Lecture reprise.",
+		es: "Música reanudada.",
+	},
+	"music.queue_cleared.title": {
+		en: "Queue Cleared",
+		de: "Warteschlange geleert",
+		fr: "File d'attente vidée",
+		es: "Cola vaciada",
+	},
+	"music.queue_cleared.one": {
+		en: "Cleared %d track from the queue.",
+		de: "%d Titel aus der Warteschlange entfernt.",
+		fr: "%d titre retiré de la file d'attente.",
+		es: "Se quitó %d pista de la cola.",
+	},
+	"music.queue_cleared.other": {
+		en: "Cleared %d tracks from the queue.",
+		de: "%d Titel aus der Warteschlange entfernt.",
+		fr: "%d titres retirés de la file d'attente.",
+		es: "Se quitaron %d pistas de la cola.",
+	},
+	"music.shuffled": {
+		en: "The queue has been shuffled.",
+		de: "Die Warteschlange wurde gemischt.",
+		fr: "La file d'attente a été mélangée.",
+		es: "La cola se ha mezclado.",
+	},
+	"music.left": {
+		en: "Left the voice channel and cleared the queue.",
+		de: "Sprachkanal verlassen und Warteschlange geleert.",
+		fr: "J'ai quitté le salon vocal et vidé la file d'attente.",
+		es: "Salí del canal de voz y vacié la cola.",
+	},
+	"music.skipped_all": {
+		en: "Skipped all tracks. No more tracks in the queue. Stopped playing.",
+		de: "Alle Titel übersprungen. Die Warteschlange ist leer, die Wiedergabe wurde beendet.",
+		fr: "Tous les titres ont été passés. La file est vide, la lecture est arrêtée.",
+		es: "Se saltaron todas las pistas. La cola está vacía y la reproducción se detuvo.",
+	},
+	"music.skipped.title": {
+		en: "Skipped Track(s)",
+		de: "Übersprungen",
+		fr: "Titres passés",
+		es: "Pistas saltadas",
+	},
+	"music.skipped.one": {
+		en: "Skipped %d track.\n\nNow playing: **%s**",
+		de: "%d Titel übersprungen.\n\nJetzt läuft: **%s**",
+		fr: "%d titre passé.\n\nEn cours de lecture : **%s**",
+		es: "Se saltó %d pista.\n\nAhora suena: **%s**",
+	},
+	"music.skipped.other": {
+		en: "Skipped %d tracks.\n\nNow playing: **%s**",
+		de: "%d Titel übersprungen.\n\nJetzt läuft: **%s**",
+		fr: "%d titres passés.\n\nEn cours de lecture : **%s**",
+		es: "Se saltaron %d pistas.\n\nAhora suena: **%s**",
+	},
+	"music.links_queued.title": {
+		en: "Links Queued",
+		de: "Links eingereiht",
+		fr: "Liens ajoutés",
+		es: "Enlaces en cola",
+	},
+	"music.links_queued.one": {
+		en: "Queued %d link.",
+		de: "%d Link eingereiht.",
+		fr: "%d lien ajouté à la file.",
+		es: "Se añadió %d enlace a la cola.",
+	},
+	"music.links_queued.other": {
+		en: "Queued %d links.",
+		de: "%d Links eingereiht.",
+		fr: "%d liens ajoutés à la file.",
+		es: "Se añadieron %d enlaces a la cola.",
+	},
+	"music.links_queued_partial": {
+		en: "Queued %d of %d links.",
+		de: "%d von %d Links eingereiht.",
+		fr: "%d liens sur %d ajoutés à la file.",
+		es: "Se añadieron %d de %d enlaces a la cola.",
+	},
+	"music.listening": {
+		en: "%s is listening to **%s** by **%s**.",
+		de: "%s hört **%s** von **%s**.",
+		fr: "%s écoute **%s** par **%s**.",
+		es: "%s está escuchando **%s** de **%s**.",
+	},
+	"music.requested.title": {
+		en: "Up Next",
+		de: "Als Nächstes",
+		fr: "À suivre",
+		es: "A continuación",
+	},
+	"music.requested": {
+		en: "Tracks %s queued, by position:\n\n%s",
+		de: "Von %s eingereihte Titel nach Position:\n\n%s",
+		fr: "Titres ajoutés par %s, par position :\n\n%s",
+		es: "Pistas que %s puso en cola, por posición:\n\n%s",
+	},
+	"music.track_entry": {
+		en: "%d. %s by %s",
+		de: "%d. %s von %s",
+		fr: "%d. %s par %s",
+		es: "%d. %s de %s",
+	},
+	"music.autoplay_label": {
+		en: "%s (autoplay)",
+		de: "%s (Autoplay)",
+		fr: "%s (lecture auto)",
+		es: "%s (reproducción automática)",
+	},
+	"music.autoplay_enabled": {
+		en: "Autoplay enabled. When the queue runs out I will keep playing related tracks.",
+		de: "Autoplay ist an. Wenn die Warteschlange leer ist, spiele ich ähnliche Titel weiter.",
+		fr: "Lecture automatique activée. Quand la file sera vide, je continuerai avec des titres similaires.",
+		es: "Reproducción automática activada. Cuando se acabe la cola seguiré con pistas similares.",
+	},
+	"music.autoplay_disabled": {
+		en: "Autoplay disabled.",
+		de: "Autoplay ist aus.",
+		fr: "Lecture automatique désactivée.",
+		es: "Reproducción automática desactivada.",
+	},
+	"music.state.on": {
+		en: "on",
+		de: "an",
+		fr: "activé",
+		es: "activado",
+	},
+	"music.state.off": {
+		en: "off",
+		de: "aus",
+		fr: "désactivé",
+		es: "desactivado",
+	},
+	"music.loop.off": {
+		en: "off",
+		de: "aus",
+		fr: "désactivée",
+		es: "desactivada",
+	},
+	"music.loop.track": {
+		en: "track",
+		de: "Titel",
+		fr: "titre",
+		es: "pista",
+	},
+	"music.loop.queue": {
+		en: "queue",
+		de: "Warteschlange",
+		fr: "file",
+		es: "cola",
+	},
+	"music.loop_set": {
+		en: "Loop mode set to **%s**.",
+		de: "Wiederholung auf **%s** gestellt.",
+		fr: "Mode boucle réglé sur **%s**.",
+		es: "Modo de repetición: **%s**.",
+	},
+	"music.rewound": {
+		en: "Rewound %d seconds.",
+		de: "%d Sekunden zurückgespult.",
+		fr: "Retour de %d secondes.",
+		es: "Retrocedí %d segundos.",
+	},
+	"music.stopped": {
+		en: "Stopped playback and cleared the queue.",
+		de: "Wiedergabe beendet und Warteschlange geleert.",
+		fr: "Lecture arrêtée et file d'attente vidée.",
+		es: "Reproducción detenida y cola vaciada.",
+	},
+	"music.queue.title": {
+		en: "Music Queue",
+		de: "Warteschlange",
+		fr: "File d'attente",
+		es: "Cola de reproducción",
+	},
+	"music.queue.now_playing": {
+		en: "Now playing: **%s** by %s",
+		de: "Jetzt läuft: **%s** von %s",
+		fr: "En cours de lecture : **%s** par %s",
+		es: "Ahora suena: **%s** de %s",
+	},
+	"music.queue.empty": {
+		en: "The queue is currently empty.",
+		de: "Die Warteschlange ist gerade leer.",
+		fr: "La file d'attente est vide pour le moment.",
+		es: "La cola está vacía.",
+	},
+	"music.queue.more": {
+		en: "...and %d more",
+		de: "...und %d weitere",
+		fr: "...et %d de plus",
+		es: "...y %d más",
+	},
+	"music.queue.footer": {
+		en: "Loop: %s | Autoplay: %s",
+		de: "Wiederholung: %s | Autoplay: %s",
+		fr: "Boucle : %s | Lecture auto : %s",
+		es: "Repetición: %s | Reproducción automática: %s",
+	},
+
+	// Control panel
+	"panel.now_playing": {
+		en: "**%s**\nby *%s*\n\nNext in queue: %d\nLoop: %s",
+		de: "**%s**\nvon *%s*\n\nIn der Warteschlange: %d\nWiederholung: %s",
+		fr: "**%s**\npar *%s*\n\nDans la file : %d\nBoucle : %s",
+		es: "**%s**\nde *%s*\n\nEn la cola: %d\nRepetición: %s",
+	},
+	"panel.rewind": {
+		en: "⏪ Rewind",
+		de: "⏪ Zurück",
+		fr: "⏪ Reculer",
+		es: "⏪ Retroceder",
+	},
+	"panel.play_pause": {
+		en: "⏯️ Play/Pause",
+		de: "⏯️ Wiedergabe/Pause",
+		fr: "⏯️ Lecture/Pause",
+		es: "⏯️ Reproducir/Pausa",
+	},
+	"panel.skip": {
+		en: "⏩ Skip",
+		de: "⏩ Überspringen",
+		fr: "⏩ Passer",
+		es: "⏩ Saltar",
+	},
+	"panel.stop": {
+		en: "⏹️ Stop",
+		de: "⏹️ Stopp",
+		fr: "⏹️ Arrêter",
+		es: "⏹️ Detener",
+	},
+	"panel.loop": {
+		en: "🔁 Loop",
+		de: "🔁 Wiederholen",
+		fr: "🔁 Boucle",
+		es: "🔁 Repetir",
+	},
+	"panel.shuffle": {
+		en: "🔀 Shuffle",
+		de: "🔀 Mischen",
+		fr: "🔀 Mélanger",
+		es: "🔀 Mezclar",
+	},
+	"panel.queue": {
+		en: "📜 Queue",
+		de: "📜 Warteschlange",
+		fr: "📜 File",
+		es: "📜 Cola",
+	},
+	"panel.like": {
+		en: "❤️ Like",
+		de: "❤️ Gefällt mir",
+		fr: "❤️ J'aime",
+		es: "❤️ Me gusta",
+	},
+
+	// Favourites
+	"favourites.title": {
+		en: "Your Favourites",
+		de: "Deine Favoriten",
+		fr: "Tes favoris",
+		es: "Tus favoritos",
+	},
+	"favourites.saved": {
+		en: "Saved **%s** to your favourites.",
+		de: "**%s** zu deinen Favoriten hinzugefügt.",
+		fr: "**%s** a été ajouté à tes favoris.",
+		es: "**%s** se guardó en tus favoritos.",
+	},
+	"favourites.already_saved": {
+		en: "**%s** is already in your favourites.",
+		de: "**%s** ist schon in deinen Favoriten.",
+		fr: "**%s** est déjà dans tes favoris.",
+		es: "**%s** ya está en tus favoritos.",
+	},
+
+	// 24/7 mode
+	"always_on.enabled.title": {
+		en: "24/7 Mode Enabled",
+		de: "24/7-Modus aktiviert",
+		fr: "Mode 24/7 activé",
+		es: "Modo 24/7 activado",
+	},
+	"always_on.enabled": {
+		en: "I will stay in <#%s> around the clock.",
+		de: "Ich bleibe rund um die Uhr in <#%s>.",
+		fr: "Je resterai dans <#%s> jour et nuit.",
+		es: "Me quedaré en <#%s> las 24 horas.",
+	},
+	"always_on.disabled": {
+		en: "24/7 mode disabled.",
+		de: "24/7-Modus deaktiviert.",
+		fr: "Mode 24/7 désactivé.",
+		es: "Modo 24/7 desactivado.",
+	},
+
+	// Lavalink nodes
+	"nodes.title": {
+		en: "Lavalink Nodes",
+		de: "Lavalink-Nodes",
+		fr: "Nœuds Lavalink",
+		es: "Nodos de Lavalink",
+	},
+	"nodes.status": {
+		en: "Status: %s\nRegion: %s\nPlayers: %d (%d playing)\nCPU: %.1f%%\nMemory: %d MiB used\nUptime: %s",
+		de: "Status: %s\nRegion: %s\nPlayer: %d (%d spielen)\nCPU: %.1f%%\nSpeicher: %d MiB belegt\nLaufzeit: %s",
+		fr: "Statut : %s\nRégion : %s\nLecteurs : %d (%d en lecture)\nCPU : %.1f%%\nMémoire : %d Mio utilisés\nDisponibilité : %s",
+		es: "Estado: %s\nRegión: %s\nReproductores: %d (%d reproduciendo)\nCPU: %.1f%%\nMemoria: %d MiB en uso\nTiempo activo: %s",
+	},
+	"nodes.any_region": {
+		en: "any",
+		de: "beliebig",
+		fr: "toutes",
+		es: "cualquiera",
+	},
+
+	// Starboard responses
+	"starboard.forced.title": {
+		en: "Posted to Starboard",
+		de: "Aufs Starboard gesetzt",
+		fr: "Publié sur le starboard",
+		es: "Publicado en el starboard",
+	},
+	"starboard.forced": {
+		en: "The message is now on the starboard.",
+		de: "Die Nachricht ist jetzt auf dem Starboard.",
+		fr: "Le message est maintenant sur le starboard.",
+		es: "El mensaje ya está en el starboard.",
+	},
+	"starboard.source": {
+		en: "Source",
+		de: "Quelle",
+		fr: "Source",
+		es: "Origen",
+	},
+	"starboard.jump": {
+		en: "[Jump!](%s)",
+		de: "[Hinspringen!](%s)",
+		fr: "[Y aller !](%s)",
+		es: "[¡Ir!](%s)",
+	},
+	"starboard.from": {
+		en: "From #%s",
+		de: "Aus #%s",
+		fr: "Depuis #%s",
+		es: "Desde #%s",
+	},
+
+	// Text commands
+	"prefix.set": {
+		en: "Text commands are on. Use `%s` to list them.",
+		de: "Textbefehle sind an. Mit `%s` siehst du alle.",
+		fr: "Les commandes texte sont activées. Utilise `%s` pour les voir.",
+		es: "Los comandos de texto están activados. Usa `%s` para verlos.",
+	},
+	"prefix.cleared": {
+		en: "Text commands are off.",
+		de: "Textbefehle sind aus.",
+		fr: "Les commandes texte sont désactivées.",
+		es: "Los comandos de texto están desactivados.",
+	},
+	"help.title": {
+		en: "Commands",
+		de: "Befehle",
+		fr: "Commandes",
+		es: "Comandos",
+	},
+	"help.footer": {
+		en: "%s <command> shows how to use a command. <required> [optional]",
+		de: "%s <Befehl> zeigt, wie ein Befehl funktioniert. <nötig> [optional]",
+		fr: "%s <commande> montre comment utiliser une commande. <requis> [facultatif]",
+		es: "%s <comando> muestra cómo usar un comando. <obligatorio> [opcional]",
+	},
+
+	// Errors
+	"error.internal.title": {
+		en: "Something Went Wrong",
+		de: "Etwas ist schiefgelaufen",
+		fr: "Une erreur est survenue",
+		es: "Algo salió mal",
+	},
+	"error.internal": {
+		en: "An unexpected error occurred. Please try again later.",
+		de: "Ein unerwarteter Fehler ist aufgetreten. Bitte versuche es später erneut.",
+		fr: "Une erreur inattendue s'est produite. Réessaie plus tard.",
+		es: "Ocurrió un error inesperado. Inténtalo de nuevo más tarde.",
+	},
+	"error.id": {
+		en: "Error ID: %s",
+		de: "Fehler-ID: %s",
+		fr: "ID de l'erreur : %s",
+		es: "ID del error: %s",
+	},
+	"error.not_in_voice.title": {
+		en: "Not in a Voice Channel",
+		de: "Nicht in einem Sprachkanal",
+		fr: "Pas dans un salon vocal",
+		es: "No estás en un canal de voz",
+	},
+	"error.not_in_voice": {
+		en: "Join a voice channel first.",
+		de: "Tritt zuerst einem Sprachkanal bei.",
+		fr: "Rejoins d'abord un salon vocal.",
+		es: "Únete primero a un canal de voz.",
+	},
+	"error.no_player.title": {
+		en: "No Player",
+		de: "Kein Player",
+		fr: "Aucun lecteur",
+		es: "Sin reproductor",
+	},
+	"error.no_player": {
+		en: "There is no music player in this server.",
+		de: "Auf diesem Server gibt es keinen Musikplayer.",
+		fr: "Il n'y a pas de lecteur de musique sur ce serveur.",
+		es: "No hay ningún reproductor de música en este servidor.",
+	},
+	"error.nothing_playing.title": {
+		en: "Nothing Playing",
+		de: "Nichts läuft",
+		fr: "Rien en lecture",
+		es: "Nada en reproducción",
+	},
+	"error.nothing_playing": {
+		en: "No song is currently playing.",
+		de: "Gerade läuft kein Song.",
+		fr: "Aucune chanson n'est en cours de lecture.",
+		es: "No se está reproduciendo ninguna canción.",
+	},
+	"error.queue_empty.title": {
+		en: "Queue Empty",
+		de: "Warteschlange leer",
+		fr: "File d'attente vide",
+		es: "Cola vacía",
+	},
+	"error.queue_empty": {
+		en: "There are no tracks in the queue.",
+		de: "Die Warteschlange enthält keine Titel.",
+		fr: "Il n'y a aucun titre dans la file d'attente.",
+		es: "No hay pistas en la cola.",
+	},
+	"error.not_enough_to_shuffle.title": {
+		en: "Nothing to Shuffle",
+		de: "Nichts zu mischen",
+		fr: "Rien à mélanger",
+		es: "Nada que mezclar",
+	},
+	"error.not_enough_to_shuffle": {
+		en: "Not enough tracks in the queue to shuffle.",
+		de: "Zu wenige Titel in der Warteschlange zum Mischen.",
+		fr: "Pas assez de titres dans la file pour les mélanger.",
+		es: "No hay suficientes pistas en la cola para mezclar.",
+	},
+	"error.always_on_leave.title": {
+		en: "24/7 Mode",
+		de: "24/7-Modus",
+		fr: "Mode 24/7",
+		es: "Modo 24/7",
+	},
+	"error.always_on_leave": {
+		en: "24/7 mode is enabled. Use `/247 off` before asking me to leave.",
+		de: "Der 24/7-Modus ist aktiv. Nutze `/247 off`, bevor du mich gehen lässt.",
+		fr: "Le mode 24/7 est activé. Utilise `/247 off` avant de me demander de partir.",
+		es: "El modo 24/7 está activado. Usa `/247 off` antes de pedirme que salga.",
+	},
+	"error.outdated_command.title": {
+		en: "Outdated Command",
+		de: "Veralteter Befehl",
+		fr: "Commande obsolète",
+		es: "Comando desactualizado",
+	},
+	"error.outdated_command": {
+		en: "This command has changed since Discord last loaded it. Please try again in a moment.",
+		de: "Dieser Befehl hat sich geändert, seit Discord ihn zuletzt geladen hat. Bitte versuche es gleich noch einmal.",
+		fr: "Cette commande a changé depuis son dernier chargement par Discord. Réessaie dans un instant.",
+		es: "Este comando cambió desde que Discord lo cargó por última vez. Inténtalo de nuevo en un momento.",
+	},
+	"error.panel_inactive.title": {
+		en: "Panel Inactive",
+		de: "Bedienfeld inaktiv",
+		fr: "Panneau inactif",
+		es: "Panel inactivo",
+	},
+	"error.panel_inactive": {
+		en: "This control panel is no longer active. Use `/player` to open a new one.",
+		de: "Dieses Bedienfeld ist nicht mehr aktiv. Öffne mit `/player` ein neues.",
+		fr: "Ce panneau de contrôle n'est plus actif. Utilise `/player` pour en ouvrir un nouveau.",
+		es: "Este panel de control ya no está activo. Usa `/player` para abrir uno nuevo.",
+	},
+	"error.guild_only.title": {
+		en: "Server Only",
+		de: "Nur auf Servern",
+		fr: "Serveur uniquement",
+		es: "Solo en servidores",
+	},
+	"error.guild_only": {
+		en: "This command can only be used in a server.",
+		de: "Dieser Befehl kann nur auf einem Server verwendet werden.",
+		fr: "Cette commande ne peut être utilisée que sur un serveur.",
+		es: "Este comando solo se puede usar en un servidor.",
+	},
+	"error.music_unavailable.title": {
+		en: "Music Unavailable",
+		de: "Musik nicht verfügbar",
+		fr: "Musique indisponible",
+		es: "Música no disponible",
+	},
+	"error.music_unavailable": {
+		en: "Music is currently unavailable while the audio server is reconnecting. Please try again shortly.",
+		de: "Musik ist nicht verfügbar, während sich der Audioserver neu verbindet. Bitte versuche es gleich noch einmal.",
+		fr: "La musique est indisponible pendant que le serveur audio se reconnecte. Réessaie dans un instant.",
+		es: "La música no está disponible mientras el servidor de audio se reconecta. Inténtalo de nuevo en breve.",
+	},
+	"error.timeout.title": {
+		en: "Timed Out",
+		de: "Zeitüberschreitung",
+		fr: "Délai dépassé",
+		es: "Tiempo agotado",
+	},
+	"error.timeout": {
+		en: "The music server or Discord took too long to respond. Please try again in a moment.",
+		de: "Der Musikserver oder Discord hat zu lange gebraucht. Bitte versuche es gleich noch einmal.",
+		fr: "Le serveur de musique ou Discord a mis trop de temps à répondre. Réessaie dans un instant.",
+		es: "El servidor de música o Discord tardó demasiado en responder. Inténtalo de nuevo en un momento.",
+	},
+	"error.shutting_down.title": {
+		en: "Shutting Down",
+		de: "Wird beendet",
+		fr: "Arrêt en cours",
+		es: "Apagándose",
+	},
+	"error.shutting_down": {
+		en: "The bot is shutting down. Please try again once it is back.",
+		de: "Der Bot wird gerade beendet. Bitte versuche es erneut, sobald er zurück ist.",
+		fr: "Le bot s'arrête. Réessaie quand il sera de retour.",
+		es: "El bot se está apagando. Inténtalo de nuevo cuando vuelva.",
+	},
+	"error.no_links.title": {
+		en: "Nothing to Play",
+		de: "Nichts abzuspielen",
+		fr: "Rien à lire",
+		es: "Nada que reproducir",
+	},
+	"error.no_links": {
+		en: "That message has no links or audio attachments.",
+		de: "Diese Nachricht enthält keine Links oder Audioanhänge.",
+		fr: "Ce message ne contient ni lien ni pièce jointe audio.",
+		es: "Ese mensaje no tiene enlaces ni archivos de audio.",
+	},
+	"error.not_a_link.title": {
+		en: "Nothing to Play",
+		de: "Nichts abzuspielen",
+		fr: "Rien à lire",
+		es: "Nada que reproducir",
+	},
+	"error.not_a_link": {
+		en: "Give me a link to play, like a YouTube or SoundCloud URL.",
+		de: "Gib mir einen Link zum Abspielen, z. B. eine YouTube- oder SoundCloud-URL.",
+		fr: "Donne-moi un lien à lire, comme une URL YouTube ou SoundCloud.",
+		es: "Dame un enlace para reproducir, como una URL de YouTube o SoundCloud.",
+	},
+	"error.invalid_prefix.title": {
+		en: "Invalid Prefix",
+		de: "Ungültiges Präfix",
+		fr: "Préfixe invalide",
+		es: "Prefijo no válido",
+	},
+	"error.invalid_prefix": {
+		en: "A prefix has 1 to %d characters and no spaces.",
+		de: "Ein Präfix hat 1 bis %d Zeichen und keine Leerzeichen.",
+		fr: "Un préfixe compte de 1 à %d caractères, sans espace.",
+		es: "Un prefijo tiene de 1 a %d caracteres y ningún espacio.",
+	},
+	"error.invalid_arguments.title": {
+		en: "Invalid Arguments",
+		de: "Ungültige Argumente",
+		fr: "Arguments invalides",
+		es: "Argumentos no válidos",
+	},
+	"error.invalid_arguments": {
+		en: "That doesn't fit the command. Usage:\n```\n%s\n```",
+		de: "Das passt nicht zum Befehl. Verwendung:\n```\n%s\n```",
+		fr: "Cela ne correspond pas à la commande. Utilisation :\n```\n%s\n```",
+		es: "Eso no encaja con el comando. Uso:\n```\n%s\n```",
+	},
+	"error.unknown_command.title": {
+		en: "Unknown Command",
+		de: "Unbekannter Befehl",
+		fr: "Commande inconnue",
+		es: "Comando desconocido",
+	},
+	"error.unknown_command": {
+		en: "There is no such command. Use `%s` to list them.",
+		de: "Diesen Befehl gibt es nicht. Mit `%s` siehst du alle.",
+		fr: "Cette commande n'existe pas. Utilise `%s` pour les voir.",
+		es: "Ese comando no existe. Usa `%s` para verlos.",
+	},
+	"error.already_on_starboard.title": {
+		en: "Already on the Starboard",
+		de: "Schon auf dem Starboard",
+		fr: "Déjà sur le starboard",
+		es: "Ya está en el starboard",
+	},
+	"error.already_on_starboard": {
+		en: "That message is already on the starboard.",
+		de: "Diese Nachricht ist bereits auf dem Starboard.",
+		fr: "Ce message est déjà sur le starboard.",
+		es: "Ese mensaje ya está en el starboard.",
+	},
+	"error.no_favourites.title": {
+		en: "No Favourites",
+		de: "Keine Favoriten",
+		fr: "Aucun favori",
+		es: "Sin favoritos",
+	},
+	"error.no_favourites": {
+		en: "You have no favourites yet. Use the ❤️ button on the player to save a track.",
+		de: "Du hast noch keine Favoriten. Speichere Titel mit dem ❤️-Button im Player.",
+		fr: "Tu n'as pas encore de favoris. Utilise le bouton ❤️ du lecteur pour enregistrer un titre.",
+		es: "Aún no tienes favoritos. Usa el botón ❤️ del reproductor para guardar una pista.",
+	},
+	"error.nothing_queued.title": {
+		en: "Nothing Queued",
+		de: "Nichts in der Warteschlange",
+		fr: "Rien dans la file",
+		es: "Nada en cola",
+	},
+	"error.nothing_queued": {
+		en: "%s has nothing playing or queued.",
+		de: "Bei %s läuft nichts und nichts ist in der Warteschlange.",
+		fr: "%s n'a rien en lecture ni dans la file.",
+		es: "%s no tiene nada sonando ni en cola.",
+	},
+	"error.permission_denied.title": {
+		en: "Permission Denied",
+		de: "Keine Berechtigung",
+		fr: "Permission refusée",
+		es: "Permiso denegado",
+	},
+	"error.permission_denied": {
+		en: "You need the %s permission to use this.",
+		de: "Du brauchst die Berechtigung %s, um das zu nutzen.",
+		fr: "Tu as besoin de la permission %s pour utiliser ceci.",
+		es: "Necesitas el permiso %s para usar esto.",
+	},
+	"error.cooldown.title": {
+		en: "Slow Down",
+		de: "Nicht so schnell",
+		fr: "Doucement",
+		es: "Más despacio",
+	},
+	"error.cooldown": {
+		en: "You can use this again in %s.",
+		de: "Du kannst das in %s wieder nutzen.",
+		fr: "Tu pourras réutiliser ceci dans %s.",
+		es: "Podrás volver a usar esto en %s.",
+	},
+	"error.no_matches.title": {
+		en: "Load Failed",
+		de: "Laden fehlgeschlagen",
+		fr: "Échec du chargement",
+		es: "Error de carga",
+	},
+	"error.no_matches": {
+		en: "I couldn't find anything to play for <%s>.",
+		de: "Ich habe für <%s> nichts zum Abspielen gefunden.",
+		fr: "Je n'ai rien trouvé à lire pour <%s>.",
+		es: "No encontré nada que reproducir para <%s>.",
+	},
+	"error.load_failed.title": {
+		en: "Load Failed",
+		de: "Laden fehlgeschlagen",
+		fr: "Échec du chargement",
+		es: "Error de carga",
+	},
+	"error.load_failed": {
+		en: "I couldn't load <%s>. It may be private, region locked or unsupported.",
+		de: "Ich konnte <%s> nicht laden. Es ist vielleicht privat, regional gesperrt oder wird nicht unterstützt.",
+		fr: "Je n'ai pas pu charger <%s>. Il est peut-être privé, bloqué dans ta région ou non pris en charge.",
+		es: "No pude cargar <%s>. Puede ser privado, estar bloqueado en tu región o no ser compatible.",
+	},
+}
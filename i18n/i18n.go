@@ -0,0 +1,67 @@
+// Package i18n holds the text the bot shows to users in every language it speaks. Messages are looked
+// up by ID in a catalog of per-locale translations; text missing for a locale falls back to English.
+package i18n
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// Fallback is the locale used when a message has no translation for the requested locale.
+const Fallback = discord.LocaleEnglishUS
+
+// locales are the locales the catalog is translated into, besides English.
+var locales = []discord.Locale{discord.LocaleGerman, discord.LocaleFrench, discord.LocaleSpanishES}
+
+// Supported reports whether the catalog is translated into locale. British English is served the fallback.
+func Supported(locale discord.Locale) bool {
+	return locale == Fallback || locale == discord.LocaleEnglishGB || slices.Contains(locales, locale)
+}
+
+// Text returns the message with the given ID in locale, formatted with args like fmt.Sprintf. Unknown IDs
+// are logged and returned as is so that a missing entry is visible without breaking the response.
+func Text(locale discord.Locale, id string, args ...any) string {
+	translations, ok := catalog[id]
+	if !ok {
+		slog.Warn("Missing message in catalog", "id", id)
+		return id
+	}
+	text, ok := translations[locale]
+	if !ok {
+		text = translations[Fallback]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Count returns the message for count items, using the ID followed by ".one" for a single item and
+// ".other" otherwise. count is the first formatting argument.
+func Count(locale discord.Locale, id string, count int, args ...any) string {
+	if count == 1 {
+		id += ".one"
+	} else {
+		id += ".other"
+	}
+	return Text(locale, id, append([]any{count}, args...)...)
+}
+
+// Localizations returns the translations of a message for the name or description localizations of an
+// application command, or nil if it has none. The English text is declared with the command itself.
+func Localizations(id string) map[discord.Locale]string {
+	var localizations map[discord.Locale]string
+	for locale, text := range catalog[id] {
+		if locale == Fallback {
+			continue
+		}
+		if localizations == nil {
+			localizations = make(map[discord.Locale]string)
+		}
+		localizations[locale] = text
+	}
+	return localizations
+}
@@ -2,50 +2,236 @@ package queue
 
 import (
 	"math/rand"
+	"slices"
+	"sync"
 
 	"github.com/disgoorg/disgolink/v3/lavalink"
 	"github.com/disgoorg/snowflake/v2"
 )
 
+// LoopMode controls what happens when the current track finishes.
+type LoopMode int
+
+const (
+	LoopOff LoopMode = iota
+	LoopTrack
+	LoopQueue
+)
+
+// Next returns the mode that follows m when cycling through loop modes.
+func (m LoopMode) Next() LoopMode {
+	return (m + 1) % 3
+}
+
+func (m LoopMode) String() string {
+	switch m {
+	case LoopTrack:
+		return "track"
+	case LoopQueue:
+		return "queue"
+	default:
+		return "off"
+	}
+}
+
+// historySize is how many played tracks are remembered per guild.
+const historySize = 25
+
+// TrackData is attached to queued tracks as Lavalink user data.
+type TrackData struct {
+	Autoplay    bool         `json:"autoplay,omitempty"`
+	RequesterID snowflake.ID `json:"requester_id,omitempty"` // User who queued the track, zero for autoplay
+}
+
+// Data decodes the TrackData of a track. Tracks without user data yield the zero value.
+func Data(track lavalink.Track) TrackData {
+	var data TrackData
+	if len(track.UserData) > 0 {
+		_ = track.UserData.Unmarshal(&data)
+	}
+	return data
+}
+
+// Queue holds the upcoming tracks of a guild. The currently playing track is owned by the Lavalink
+// player and is not part of the queue. A queue is used from command handlers, Lavalink events, timers
+// and the persistence loop at once, so its state is only reachable through its methods.
 type Queue struct {
-	Tracks []lavalink.Track
+	mu       sync.Mutex
+	tracks   []lavalink.Track
+	loop     LoopMode
+	autoplay bool
+	history  []lavalink.Track // Recently played tracks, oldest first
 }
 
-func (q *Queue) Add(track lavalink.Track) {
-	q.Tracks = append(q.Tracks, track)
+// Add appends tracks to the end of the queue.
+func (q *Queue) Add(tracks ...lavalink.Track) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tracks = append(q.tracks, tracks...)
 }
 
+// Next removes and returns the first track of the queue.
 func (q *Queue) Next() (lavalink.Track, bool) {
-	if len(q.Tracks) == 0 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.tracks) == 0 {
 		return lavalink.Track{}, false
 	}
-	track := q.Tracks[0]
-	q.Tracks = q.Tracks[1:]
+	track := q.tracks[0]
+	q.tracks = q.tracks[1:]
 	return track, true
 }
 
-func (q *Queue) Shuffle() {
-	rand.Shuffle(len(q.Tracks[1:]), func(i, j int) {
-		q.Tracks[i+1], q.Tracks[j+1] = q.Tracks[j+1], q.Tracks[i+1]
+// Tracks returns a copy of the upcoming tracks.
+func (q *Queue) Tracks() []lavalink.Track {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.tracks)
+}
+
+// Len returns the number of upcoming tracks.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.tracks)
+}
+
+// Shuffle shuffles the upcoming tracks. It reports false if there were fewer than two to shuffle.
+func (q *Queue) Shuffle() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.tracks) <= 1 {
+		return false
+	}
+	rand.Shuffle(len(q.tracks), func(i, j int) {
+		q.tracks[i], q.tracks[j] = q.tracks[j], q.tracks[i]
 	})
+	return true
 }
 
-func (q *Queue) Skip(amount int) (lavalink.Track, bool) {
-	if len(q.Tracks) == 0 {
-		return lavalink.Track{}, false
+// Skip removes up to amount tracks from the front of the queue and returns how many it removed. While
+// the whole queue loops, the skipped tracks move to the end instead.
+func (q *Queue) Skip(amount int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	amount = min(max(amount, 0), len(q.tracks))
+	skipped := q.tracks[:amount]
+	q.tracks = q.tracks[amount:]
+	if q.loop == LoopQueue {
+		q.tracks = append(slices.Clone(q.tracks), skipped...)
+	}
+	return amount
+}
+
+// Finished re-queues a track that played to the end according to the loop mode.
+func (q *Queue) Finished(track lavalink.Track) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	switch q.loop {
+	case LoopTrack:
+		q.tracks = append([]lavalink.Track{track}, q.tracks...)
+	case LoopQueue:
+		q.tracks = append(q.tracks, track)
 	}
-	if amount > len(q.Tracks) {
-		amount = len(q.Tracks)
+}
+
+// Played records a track in the history, dropping the oldest entries beyond historySize.
+func (q *Queue) Played(track lavalink.Track) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.history = append(q.history, track)
+	if len(q.history) > historySize {
+		q.history = q.history[len(q.history)-historySize:]
 	}
-	q.Tracks = q.Tracks[amount:]
-	if len(q.Tracks) == 0 {
+}
+
+// LastPlayed returns the most recently played track.
+func (q *Queue) LastPlayed() (lavalink.Track, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.history) == 0 {
 		return lavalink.Track{}, false
 	}
-	return q.Tracks[0], true
+	return q.history[len(q.history)-1], true
+}
+
+// RecentlyPlayed reports whether a track with the same identifier is in the history.
+func (q *Queue) RecentlyPlayed(track lavalink.Track) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, played := range q.history {
+		if played.Info.Identifier == track.Info.Identifier {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all upcoming tracks and returns how many there were.
+func (q *Queue) Clear() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	cleared := len(q.tracks)
+	q.tracks = nil
+	return cleared
+}
+
+// Loop returns the loop mode.
+func (q *Queue) Loop() LoopMode {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.loop
+}
+
+// SetLoop sets the loop mode.
+func (q *Queue) SetLoop(mode LoopMode) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.loop = mode
+}
+
+// CycleLoop switches to the next loop mode and returns it.
+func (q *Queue) CycleLoop() LoopMode {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.loop = q.loop.Next()
+	return q.loop
+}
+
+// Autoplay reports whether related tracks are played when the queue runs out.
+func (q *Queue) Autoplay() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.autoplay
+}
+
+// SetAutoplay turns autoplay on or off.
+func (q *Queue) SetAutoplay(enabled bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.autoplay = enabled
+}
+
+// ToggleAutoplay turns autoplay on if it was off and the other way round, and returns the new setting.
+func (q *Queue) ToggleAutoplay() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.autoplay = !q.autoplay
+	return q.autoplay
+}
+
+// Restore replaces the upcoming tracks and settings with persisted ones.
+func (q *Queue) Restore(tracks []lavalink.Track, loop LoopMode, autoplay bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tracks = tracks
+	q.loop = loop
+	q.autoplay = autoplay
 }
 
 type QueueManager struct {
 	Queues map[snowflake.ID]*Queue
+	mu     sync.Mutex
 }
 
 func NewQueueManager() *QueueManager {
@@ -55,6 +241,8 @@ func NewQueueManager() *QueueManager {
 }
 
 func (qm *QueueManager) Get(guildID snowflake.ID) *Queue {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
 	queue, ok := qm.Queues[guildID]
 	if !ok {
 		queue = &Queue{}
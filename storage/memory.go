@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Memory keeps the bot's data in memory. It is used when no database is configured, so
+// everything it holds is lost on restart.
+type Memory struct {
+	mu         sync.Mutex
+	starred    map[snowflake.ID]*starredRecord
+	alwaysOn   map[snowflake.ID]AlwaysOnSettings
+	prefixes   map[snowflake.ID]string
+	sessions   map[string]string
+	favourites map[snowflake.ID][]favouriteRecord // Oldest first
+	players    map[snowflake.ID]SavedPlayer
+}
+
+type starredRecord struct {
+	message            StarredMessage
+	stars              int
+	starboardMessageID snowflake.ID
+}
+
+type favouriteRecord struct {
+	identifier string
+	favourite  Favourite
+}
+
+// NewMemory creates an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		starred:    make(map[snowflake.ID]*starredRecord),
+		alwaysOn:   make(map[snowflake.ID]AlwaysOnSettings),
+		prefixes:   make(map[snowflake.ID]string),
+		sessions:   make(map[string]string),
+		favourites: make(map[snowflake.ID][]favouriteRecord),
+		players:    make(map[snowflake.ID]SavedPlayer),
+	}
+}
+
+var _ Store = (*Memory)(nil)
+
+func (m *Memory) AddStar(_ context.Context, message StarredMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.starred[message.MessageID]; ok {
+		record.stars++
+		return nil
+	}
+	m.starred[message.MessageID] = &starredRecord{message: message, stars: 1}
+	return nil
+}
+
+func (m *Memory) RecordStarred(_ context.Context, message StarredMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.starred[message.MessageID]; !ok {
+		m.starred[message.MessageID] = &starredRecord{message: message}
+	}
+	return nil
+}
+
+func (m *Memory) RemoveStar(_ context.Context, messageID snowflake.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.starred[messageID]; ok && record.stars > 0 {
+		record.stars--
+	}
+	return nil
+}
+
+func (m *Memory) StarCount(_ context.Context, messageID snowflake.ID) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.starred[messageID]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return record.stars, nil
+}
+
+func (m *Memory) StarboardMessageID(_ context.Context, messageID snowflake.ID) (snowflake.ID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.starred[messageID]
+	if !ok || record.starboardMessageID == 0 {
+		return 0, ErrNotFound
+	}
+	return record.starboardMessageID, nil
+}
+
+func (m *Memory) SetStarboardMessageID(_ context.Context, messageID, starboardMessageID snowflake.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.starred[messageID]; ok {
+		record.starboardMessageID = starboardMessageID
+	}
+	return nil
+}
+
+func (m *Memory) DeleteStarredMessage(_ context.Context, messageID snowflake.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.starred, messageID)
+	return nil
+}
+
+func (m *Memory) AlwaysOn(_ context.Context, guildID snowflake.ID) (AlwaysOnSettings, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	settings, ok := m.alwaysOn[guildID]
+	return settings, ok, nil
+}
+
+func (m *Memory) SetAlwaysOn(_ context.Context, guildID snowflake.ID, settings AlwaysOnSettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alwaysOn[guildID] = settings
+	return nil
+}
+
+func (m *Memory) ClearAlwaysOn(_ context.Context, guildID snowflake.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.alwaysOn, guildID)
+	return nil
+}
+
+func (m *Memory) Prefix(_ context.Context, guildID snowflake.ID) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.prefixes[guildID], nil
+}
+
+func (m *Memory) SetPrefix(_ context.Context, guildID snowflake.ID, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prefixes[guildID] = prefix
+	return nil
+}
+
+func (m *Memory) ClearPrefix(_ context.Context, guildID snowflake.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.prefixes, guildID)
+	return nil
+}
+
+func (m *Memory) LavalinkSessionID(_ context.Context, nodeName string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sessions[nodeName], nil
+}
+
+func (m *Memory) SetLavalinkSessionID(_ context.Context, nodeName, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[nodeName] = sessionID
+	return nil
+}
+
+func (m *Memory) AddFavourite(_ context.Context, userID snowflake.ID, track lavalink.Track) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, record := range m.favourites[userID] {
+		if record.identifier == track.Info.Identifier {
+			return false, nil
+		}
+	}
+
+	favourite := Favourite{Title: track.Info.Title, Author: track.Info.Author}
+	if track.Info.URI != nil {
+		favourite.URI = *track.Info.URI
+	}
+	m.favourites[userID] = append(m.favourites[userID], favouriteRecord{identifier: track.Info.Identifier, favourite: favourite})
+	return true, nil
+}
+
+func (m *Memory) Favourites(_ context.Context, userID snowflake.ID, limit int) ([]Favourite, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	records := m.favourites[userID]
+	var favourites []Favourite
+	for i := len(records) - 1; i >= 0 && len(favourites) < limit; i-- {
+		favourites = append(favourites, records[i].favourite)
+	}
+	return favourites, nil
+}
+
+func (m *Memory) SavePlayers(_ context.Context, players []SavedPlayer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, player := range players {
+		player.Queue = slices.Clone(player.Queue)
+		m.players[player.GuildID] = player
+	}
+	return nil
+}
+
+func (m *Memory) SavedPlayer(_ context.Context, guildID snowflake.ID) (SavedPlayer, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	player, ok := m.players[guildID]
+	player.Queue = slices.Clone(player.Queue)
+	return player, ok, nil
+}
+
+func (m *Memory) DeleteSavedPlayer(_ context.Context, guildID snowflake.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.players, guildID)
+	return nil
+}
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unccord-bot-go/queue"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Postgres stores the bot's data in PostgreSQL, using the tables created from the SQL directory.
+type Postgres struct {
+	db *sql.DB
+}
+
+// NewPostgres creates a store on an open connection pool.
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+var _ Store = (*Postgres)(nil)
+
+func (p *Postgres) AddStar(ctx context.Context, message StarredMessage) error {
+	query := `INSERT INTO starboard(message_id, channel_id, author_id, content, star_count)
+	VALUES($1, $2, $3, $4, 1)
+	ON CONFLICT(message_id) DO UPDATE SET star_count = starboard.star_count + 1`
+	_, err := p.db.ExecContext(ctx, query, message.MessageID.String(), message.ChannelID.String(), message.AuthorID.String(), message.Content)
+	return err
+}
+
+func (p *Postgres) RecordStarred(ctx context.Context, message StarredMessage) error {
+	query := `INSERT INTO starboard(message_id, channel_id, author_id, content, star_count)
+	VALUES($1, $2, $3, $4, 0)
+	ON CONFLICT(message_id) DO NOTHING`
+	_, err := p.db.ExecContext(ctx, query, message.MessageID.String(), message.ChannelID.String(), message.AuthorID.String(), message.Content)
+	return err
+}
+
+func (p *Postgres) RemoveStar(ctx context.Context, messageID snowflake.ID) error {
+	query := `UPDATE starboard SET star_count = star_count - 1 WHERE message_id = $1 AND star_count > 0`
+	_, err := p.db.ExecContext(ctx, query, messageID.String())
+	return err
+}
+
+func (p *Postgres) StarCount(ctx context.Context, messageID snowflake.ID) (int, error) {
+	var starCount int
+	query := `SELECT star_count FROM starboard WHERE message_id = $1`
+	err := p.db.QueryRowContext(ctx, query, messageID.String()).Scan(&starCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return starCount, err
+}
+
+func (p *Postgres) StarboardMessageID(ctx context.Context, messageID snowflake.ID) (snowflake.ID, error) {
+	var starboardMessageID sql.NullString
+	query := `SELECT starboard_message_id FROM starboard WHERE message_id = $1`
+	err := p.db.QueryRowContext(ctx, query, messageID.String()).Scan(&starboardMessageID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !starboardMessageID.Valid) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := snowflake.Parse(starboardMessageID.String)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing starboard message ID: %w", err)
+	}
+	return id, nil
+}
+
+func (p *Postgres) SetStarboardMessageID(ctx context.Context, messageID, starboardMessageID snowflake.ID) error {
+	query := `UPDATE starboard SET starboard_message_id = $1 WHERE message_id = $2`
+	_, err := p.db.ExecContext(ctx, query, starboardMessageID.String(), messageID.String())
+	return err
+}
+
+func (p *Postgres) DeleteStarredMessage(ctx context.Context, messageID snowflake.ID) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM starboard WHERE message_id = $1`, messageID.String())
+	return err
+}
+
+func (p *Postgres) AlwaysOn(ctx context.Context, guildID snowflake.ID) (AlwaysOnSettings, bool, error) {
+	var channelID, playlist sql.NullString
+	query := `SELECT always_on_channel_id, always_on_playlist FROM guild_settings WHERE guild_id = $1`
+	err := p.db.QueryRowContext(ctx, query, guildID.String()).Scan(&channelID, &playlist)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !channelID.Valid) {
+		return AlwaysOnSettings{}, false, nil
+	}
+	if err != nil {
+		return AlwaysOnSettings{}, false, err
+	}
+
+	id, err := snowflake.Parse(channelID.String)
+	if err != nil {
+		return AlwaysOnSettings{}, false, fmt.Errorf("error parsing 24/7 channel ID: %w", err)
+	}
+	return AlwaysOnSettings{ChannelID: id, Playlist: playlist.String}, true, nil
+}
+
+func (p *Postgres) SetAlwaysOn(ctx context.Context, guildID snowflake.ID, settings AlwaysOnSettings) error {
+	query := `INSERT INTO guild_settings(guild_id, always_on_channel_id, always_on_playlist)
+	VALUES($1, $2, NULLIF($3, ''))
+	ON CONFLICT(guild_id) DO UPDATE SET always_on_channel_id = $2, always_on_playlist = NULLIF($3, ''), updated_at = NOW()`
+	_, err := p.db.ExecContext(ctx, query, guildID.String(), settings.ChannelID.String(), settings.Playlist)
+	return err
+}
+
+func (p *Postgres) ClearAlwaysOn(ctx context.Context, guildID snowflake.ID) error {
+	query := `UPDATE guild_settings SET always_on_channel_id = NULL, always_on_playlist = NULL, updated_at = NOW() WHERE guild_id = $1`
+	_, err := p.db.ExecContext(ctx, query, guildID.String())
+	return err
+}
+
+func (p *Postgres) Prefix(ctx context.Context, guildID snowflake.ID) (string, error) {
+	var prefix string
+	query := `SELECT prefix FROM guild_prefixes WHERE guild_id = $1`
+	err := p.db.QueryRowContext(ctx, query, guildID.String()).Scan(&prefix)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return prefix, err
+}
+
+func (p *Postgres) SetPrefix(ctx context.Context, guildID snowflake.ID, prefix string) error {
+	query := `INSERT INTO guild_prefixes(guild_id, prefix)
+	VALUES($1, $2)
+	ON CONFLICT(guild_id) DO UPDATE SET prefix = $2, updated_at = NOW()`
+	_, err := p.db.ExecContext(ctx, query, guildID.String(), prefix)
+	return err
+}
+
+func (p *Postgres) ClearPrefix(ctx context.Context, guildID snowflake.ID) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM guild_prefixes WHERE guild_id = $1`, guildID.String())
+	return err
+}
+
+func (p *Postgres) LavalinkSessionID(ctx context.Context, nodeName string) (string, error) {
+	var sessionID string
+	query := `SELECT session_id FROM lavalink_sessions WHERE node_name = $1`
+	err := p.db.QueryRowContext(ctx, query, nodeName).Scan(&sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return sessionID, err
+}
+
+func (p *Postgres) SetLavalinkSessionID(ctx context.Context, nodeName, sessionID string) error {
+	query := `INSERT INTO lavalink_sessions(node_name, session_id)
+	VALUES($1, $2)
+	ON CONFLICT(node_name) DO UPDATE SET session_id = $2, updated_at = NOW()`
+	_, err := p.db.ExecContext(ctx, query, nodeName, sessionID)
+	return err
+}
+
+func (p *Postgres) AddFavourite(ctx context.Context, userID snowflake.ID, track lavalink.Track) (bool, error) {
+	uri := ""
+	if track.Info.URI != nil {
+		uri = *track.Info.URI
+	}
+
+	query := `INSERT INTO favourites(user_id, identifier, title, author, uri, encoded)
+	VALUES($1, $2, $3, $4, $5, $6)
+	ON CONFLICT(user_id, identifier) DO NOTHING`
+	result, err := p.db.ExecContext(ctx, query, userID.String(), track.Info.Identifier, track.Info.Title, track.Info.Author, uri, track.Encoded)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (p *Postgres) Favourites(ctx context.Context, userID snowflake.ID, limit int) ([]Favourite, error) {
+	query := `SELECT title, author, COALESCE(uri, '') FROM favourites WHERE user_id = $1 ORDER BY saved_at DESC LIMIT $2`
+	rows, err := p.db.QueryContext(ctx, query, userID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var favourites []Favourite
+	for rows.Next() {
+		var favourite Favourite
+		if err := rows.Scan(&favourite.Title, &favourite.Author, &favourite.URI); err != nil {
+			return nil, err
+		}
+		favourites = append(favourites, favourite)
+	}
+	return favourites, rows.Err()
+}
+
+func (p *Postgres) SavePlayers(ctx context.Context, players []SavedPlayer) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO player_state(guild_id, voice_channel_id, current_track, position, paused, volume, filters, loop_mode, autoplay, queue)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT(guild_id) DO UPDATE SET voice_channel_id = $2, current_track = $3, position = $4, paused = $5, volume = $6,
+	filters = $7, loop_mode = $8, autoplay = $9, queue = $10, saved_at = NOW()`
+	for _, player := range players {
+		// lib/pq sends an empty []byte as an empty string, which is not valid JSON, so a player without a
+		// track needs an untyped nil to store NULL
+		var track any
+		if player.Track != nil {
+			encoded, err := json.Marshal(player.Track)
+			if err != nil {
+				return fmt.Errorf("error encoding current track: %w", err)
+			}
+			track = encoded
+		}
+		filters, err := json.Marshal(player.Filters)
+		if err != nil {
+			return fmt.Errorf("error encoding filters: %w", err)
+		}
+		tracks, err := json.Marshal(player.Queue)
+		if err != nil {
+			return fmt.Errorf("error encoding queue: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, query, player.GuildID.String(), player.VoiceChannelID.String(), track, int64(player.Position),
+			player.Paused, player.Volume, filters, int(player.Loop), player.Autoplay, tracks)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (p *Postgres) SavedPlayer(ctx context.Context, guildID snowflake.ID) (SavedPlayer, bool, error) {
+	var (
+		player                 = SavedPlayer{GuildID: guildID}
+		voiceChannelID         string
+		track, filters, tracks []byte
+		position               int64
+		loop                   int
+	)
+	query := `SELECT voice_channel_id, current_track, position, paused, volume, filters, loop_mode, autoplay, queue FROM player_state WHERE guild_id = $1`
+	err := p.db.QueryRowContext(ctx, query, guildID.String()).Scan(&voiceChannelID, &track, &position, &player.Paused, &player.Volume, &filters, &loop, &player.Autoplay, &tracks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SavedPlayer{}, false, nil
+	}
+	if err != nil {
+		return SavedPlayer{}, false, err
+	}
+
+	if player.VoiceChannelID, err = snowflake.Parse(voiceChannelID); err != nil {
+		return SavedPlayer{}, false, fmt.Errorf("error parsing voice channel ID: %w", err)
+	}
+	if len(track) > 0 {
+		if err = json.Unmarshal(track, &player.Track); err != nil {
+			return SavedPlayer{}, false, fmt.Errorf("error decoding current track: %w", err)
+		}
+	}
+	if len(filters) > 0 {
+		if err = json.Unmarshal(filters, &player.Filters); err != nil {
+			return SavedPlayer{}, false, fmt.Errorf("error decoding filters: %w", err)
+		}
+	}
+	if err = json.Unmarshal(tracks, &player.Queue); err != nil {
+		return SavedPlayer{}, false, fmt.Errorf("error decoding queue: %w", err)
+	}
+	player.Position = lavalink.Duration(position)
+	player.Loop = queue.LoopMode(loop)
+	return player, true, nil
+}
+
+func (p *Postgres) DeleteSavedPlayer(ctx context.Context, guildID snowflake.ID) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM player_state WHERE guild_id = $1`, guildID.String())
+	return err
+}
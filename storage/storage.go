@@ -0,0 +1,118 @@
+// Package storage persists the bot's data behind small interfaces so handlers do not depend on a
+// particular database. Postgres is used in production; Memory keeps everything in memory for
+// deployments without a database and for tests.
+package storage
+
+import (
+	"context"
+	"errors"
+	"unccord-bot-go/queue"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Store combines all stores used by the bot.
+type Store interface {
+	StarboardStore
+	SettingsStore
+	FavouriteStore
+	QueueStore
+}
+
+// StarredMessage is a message that received stars.
+type StarredMessage struct {
+	MessageID snowflake.ID
+	ChannelID snowflake.ID
+	AuthorID  snowflake.ID
+	Content   string
+}
+
+// StarboardStore keeps the star counts of messages and their posts on the starboard.
+type StarboardStore interface {
+	// AddStar adds a star to a message, recording the message with one star if it was not starred yet.
+	AddStar(ctx context.Context, message StarredMessage) error
+	// RecordStarred records a message without stars unless it is already recorded, so it can be posted
+	// to the starboard before it was starred.
+	RecordStarred(ctx context.Context, message StarredMessage) error
+	// RemoveStar removes a star from a message without going below zero.
+	RemoveStar(ctx context.Context, messageID snowflake.ID) error
+	// StarCount returns the stars of a message or ErrNotFound if it was never starred.
+	StarCount(ctx context.Context, messageID snowflake.ID) (int, error)
+	// StarboardMessageID returns the starboard post of a message or ErrNotFound if it was not posted.
+	StarboardMessageID(ctx context.Context, messageID snowflake.ID) (snowflake.ID, error)
+	// SetStarboardMessageID records the starboard post of a message.
+	SetStarboardMessageID(ctx context.Context, messageID, starboardMessageID snowflake.ID) error
+	// DeleteStarredMessage forgets a starred message.
+	DeleteStarredMessage(ctx context.Context, messageID snowflake.ID) error
+}
+
+// AlwaysOnSettings describes a guild's 24/7 mode: the voice channel the bot stays in
+// and an optional playlist used to refill the queue when it runs empty.
+type AlwaysOnSettings struct {
+	ChannelID snowflake.ID
+	Playlist  string
+}
+
+// SettingsStore keeps per-guild settings and bot-wide state such as Lavalink sessions.
+type SettingsStore interface {
+	// AlwaysOn retrieves the 24/7 settings of a guild. It reports false if 24/7 mode is off.
+	AlwaysOn(ctx context.Context, guildID snowflake.ID) (AlwaysOnSettings, bool, error)
+	// SetAlwaysOn enables 24/7 mode for a guild.
+	SetAlwaysOn(ctx context.Context, guildID snowflake.ID, settings AlwaysOnSettings) error
+	// ClearAlwaysOn disables 24/7 mode for a guild.
+	ClearAlwaysOn(ctx context.Context, guildID snowflake.ID) error
+	// Prefix retrieves the prefix of a guild's text commands, or an empty string if they are off.
+	Prefix(ctx context.Context, guildID snowflake.ID) (string, error)
+	// SetPrefix turns on text commands for a guild with the given prefix.
+	SetPrefix(ctx context.Context, guildID snowflake.ID, prefix string) error
+	// ClearPrefix turns off text commands for a guild.
+	ClearPrefix(ctx context.Context, guildID snowflake.ID) error
+	// LavalinkSessionID retrieves the stored session ID of a Lavalink node, or an empty string if none was stored.
+	LavalinkSessionID(ctx context.Context, nodeName string) (string, error)
+	// SetLavalinkSessionID stores the session ID of a Lavalink node so it can be resumed after a restart.
+	SetLavalinkSessionID(ctx context.Context, nodeName, sessionID string) error
+}
+
+// Favourite is a track a user saved from the control panel.
+type Favourite struct {
+	Title  string
+	Author string
+	URI    string
+}
+
+// FavouriteStore keeps the tracks users saved.
+type FavouriteStore interface {
+	// AddFavourite saves a track for a user. It reports false if the track was already saved.
+	AddFavourite(ctx context.Context, userID snowflake.ID, track lavalink.Track) (bool, error)
+	// Favourites retrieves the most recently saved favourites of a user.
+	Favourites(ctx context.Context, userID snowflake.ID, limit int) ([]Favourite, error)
+}
+
+// SavedPlayer is the state of a guild's player and queue as persisted across restarts.
+type SavedPlayer struct {
+	GuildID        snowflake.ID
+	VoiceChannelID snowflake.ID
+	Track          *lavalink.Track
+	Position       lavalink.Duration
+	Paused         bool
+	Volume         int
+	Filters        lavalink.Filters
+	Loop           queue.LoopMode
+	Autoplay       bool
+	Queue          []lavalink.Track
+}
+
+// QueueStore keeps the player states and queues of guilds across restarts.
+type QueueStore interface {
+	// SavePlayers persists the states of the given players, replacing their earlier states. Guilds that are
+	// not given keep theirs, so players that were not restored yet are not lost.
+	SavePlayers(ctx context.Context, players []SavedPlayer) error
+	// SavedPlayer retrieves the persisted player state of a guild. It reports false if none was saved.
+	SavedPlayer(ctx context.Context, guildID snowflake.ID) (SavedPlayer, bool, error)
+	// DeleteSavedPlayer removes the persisted player state of a guild.
+	DeleteSavedPlayer(ctx context.Context, guildID snowflake.ID) error
+}
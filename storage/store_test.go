@@ -0,0 +1,365 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unccord-bot-go/queue"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
+	_ "github.com/lib/pq"
+)
+
+// postgresDSNEnv names the environment variable with a connection string for running the store tests
+// against Postgres as well. Each test creates and drops its own schema, so any scratch database will do.
+const postgresDSNEnv = "STORAGE_TEST_DSN"
+
+// storeContract lists the behaviour every Store implementation has to provide.
+var storeContract = []struct {
+	name string
+	test func(t *testing.T, ctx context.Context, store Store)
+}{
+	{name: "starboard", test: testStarboard},
+	{name: "always on", test: testAlwaysOn},
+	{name: "prefixes", test: testPrefixes},
+	{name: "lavalink sessions", test: testLavalinkSessions},
+	{name: "favourites", test: testFavourites},
+	{name: "player state", test: testPlayerState},
+}
+
+func TestMemory(t *testing.T) {
+	runStoreContract(t, func(t *testing.T) Store {
+		return NewMemory()
+	})
+}
+
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+	runStoreContract(t, func(t *testing.T) Store {
+		return NewPostgres(openTestDB(t, dsn))
+	})
+}
+
+func runStoreContract(t *testing.T, newStore func(t *testing.T) Store) {
+	for _, contract := range storeContract {
+		t.Run(contract.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			contract.test(t, ctx, newStore(t))
+		})
+	}
+}
+
+// openTestDB connects to the database and creates the bot's tables in a schema of their own, which is
+// dropped when the test ends. The pool is limited to one connection so the search path sticks.
+func openTestDB(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	schema := fmt.Sprintf("storage_test_%d", time.Now().UnixNano())
+	if _, err = db.Exec(fmt.Sprintf("CREATE SCHEMA %s; SET search_path TO %s", schema, schema)); err != nil {
+		t.Fatalf("error creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema)); err != nil {
+			t.Errorf("error dropping schema: %v", err)
+		}
+	})
+
+	scripts, err := filepath.Glob(filepath.Join("..", "SQL", "*.sql"))
+	if err != nil || len(scripts) == 0 {
+		t.Fatalf("no DDL scripts found: %v", err)
+	}
+	for _, path := range scripts {
+		script, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("error reading %s: %v", path, err)
+		}
+		if _, err = db.Exec(string(script)); err != nil {
+			t.Fatalf("error executing %s: %v", path, err)
+		}
+	}
+	return db
+}
+
+func testStarboard(t *testing.T, ctx context.Context, store Store) {
+	message := StarredMessage{MessageID: 1, ChannelID: 2, AuthorID: 3, Content: "hello"}
+
+	if _, err := store.StarCount(ctx, message.MessageID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("StarCount of unknown message: got error %v, want ErrNotFound", err)
+	}
+	if _, err := store.StarboardMessageID(ctx, message.MessageID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("StarboardMessageID of unknown message: got error %v, want ErrNotFound", err)
+	}
+
+	steps := []struct {
+		name string
+		do   func() error
+		want int
+	}{
+		{name: "record", do: func() error { return store.RecordStarred(ctx, message) }, want: 0},
+		{name: "record again", do: func() error { return store.RecordStarred(ctx, message) }, want: 0},
+		{name: "first star", do: func() error { return store.AddStar(ctx, message) }, want: 1},
+		{name: "second star", do: func() error { return store.AddStar(ctx, message) }, want: 2},
+		{name: "remove star", do: func() error { return store.RemoveStar(ctx, message.MessageID) }, want: 1},
+		{name: "remove last star", do: func() error { return store.RemoveStar(ctx, message.MessageID) }, want: 0},
+		{name: "remove below zero", do: func() error { return store.RemoveStar(ctx, message.MessageID) }, want: 0},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		count, err := store.StarCount(ctx, message.MessageID)
+		if err != nil {
+			t.Fatalf("%s: StarCount: %v", step.name, err)
+		}
+		if count != step.want {
+			t.Fatalf("%s: got %d stars, want %d", step.name, count, step.want)
+		}
+	}
+
+	if _, err := store.StarboardMessageID(ctx, message.MessageID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("StarboardMessageID before posting: got error %v, want ErrNotFound", err)
+	}
+	if err := store.SetStarboardMessageID(ctx, message.MessageID, 10); err != nil {
+		t.Fatalf("SetStarboardMessageID: %v", err)
+	}
+	if id, err := store.StarboardMessageID(ctx, message.MessageID); err != nil || id != 10 {
+		t.Fatalf("StarboardMessageID = %d, %v, want 10", id, err)
+	}
+
+	if err := store.DeleteStarredMessage(ctx, message.MessageID); err != nil {
+		t.Fatalf("DeleteStarredMessage: %v", err)
+	}
+	if _, err := store.StarCount(ctx, message.MessageID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("StarCount after delete: got error %v, want ErrNotFound", err)
+	}
+}
+
+func testAlwaysOn(t *testing.T, ctx context.Context, store Store) {
+	const guildID = snowflake.ID(1)
+	if _, ok, err := store.AlwaysOn(ctx, guildID); err != nil || ok {
+		t.Fatalf("AlwaysOn before enabling = %t, %v, want false", ok, err)
+	}
+
+	for _, settings := range []AlwaysOnSettings{
+		{ChannelID: 2},
+		{ChannelID: 3, Playlist: "https://example.com/playlist"},
+		{ChannelID: 3},
+	} {
+		if err := store.SetAlwaysOn(ctx, guildID, settings); err != nil {
+			t.Fatalf("SetAlwaysOn(%+v): %v", settings, err)
+		}
+		got, ok, err := store.AlwaysOn(ctx, guildID)
+		if err != nil || !ok || got != settings {
+			t.Fatalf("AlwaysOn = %+v, %t, %v, want %+v", got, ok, err, settings)
+		}
+	}
+
+	if err := store.ClearAlwaysOn(ctx, guildID); err != nil {
+		t.Fatalf("ClearAlwaysOn: %v", err)
+	}
+	if _, ok, err := store.AlwaysOn(ctx, guildID); err != nil || ok {
+		t.Fatalf("AlwaysOn after clearing = %t, %v, want false", ok, err)
+	}
+}
+
+func testPrefixes(t *testing.T, ctx context.Context, store Store) {
+	const guildID, otherGuildID = snowflake.ID(1), snowflake.ID(2)
+	steps := []struct {
+		name string
+		do   func() error
+		want string
+	}{
+		{name: "unset", do: func() error { return nil }, want: ""},
+		{name: "set", do: func() error { return store.SetPrefix(ctx, guildID, "!") }, want: "!"},
+		{name: "change", do: func() error { return store.SetPrefix(ctx, guildID, "?") }, want: "?"},
+		{name: "other guild", do: func() error { return store.SetPrefix(ctx, otherGuildID, "$") }, want: "?"},
+		{name: "clear", do: func() error { return store.ClearPrefix(ctx, guildID) }, want: ""},
+		{name: "clear again", do: func() error { return store.ClearPrefix(ctx, guildID) }, want: ""},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if prefix, err := store.Prefix(ctx, guildID); err != nil || prefix != step.want {
+			t.Fatalf("%s: Prefix = %q, %v, want %q", step.name, prefix, err, step.want)
+		}
+	}
+	if prefix, err := store.Prefix(ctx, otherGuildID); err != nil || prefix != "$" {
+		t.Fatalf("Prefix of other guild = %q, %v, want %q", prefix, err, "$")
+	}
+}
+
+func testLavalinkSessions(t *testing.T, ctx context.Context, store Store) {
+	if sessionID, err := store.LavalinkSessionID(ctx, "main"); err != nil || sessionID != "" {
+		t.Fatalf("LavalinkSessionID before storing = %q, %v, want none", sessionID, err)
+	}
+	for _, sessionID := range []string{"first", "second"} {
+		if err := store.SetLavalinkSessionID(ctx, "main", sessionID); err != nil {
+			t.Fatalf("SetLavalinkSessionID: %v", err)
+		}
+		if got, err := store.LavalinkSessionID(ctx, "main"); err != nil || got != sessionID {
+			t.Fatalf("LavalinkSessionID = %q, %v, want %q", got, err, sessionID)
+		}
+	}
+	if sessionID, err := store.LavalinkSessionID(ctx, "backup"); err != nil || sessionID != "" {
+		t.Fatalf("LavalinkSessionID of other node = %q, %v, want none", sessionID, err)
+	}
+}
+
+func testFavourites(t *testing.T, ctx context.Context, store Store) {
+	const userID, otherUserID = snowflake.ID(1), snowflake.ID(2)
+	tracks := []lavalink.Track{testTrack("a"), testTrack("b"), testTrack("c")}
+	tracks[2].Info.URI = nil
+
+	for _, track := range tracks {
+		added, err := store.AddFavourite(ctx, userID, track)
+		if err != nil || !added {
+			t.Fatalf("AddFavourite(%s) = %t, %v, want added", track.Info.Identifier, added, err)
+		}
+		// Keep the save times of the tracks apart so their order is well defined
+		time.Sleep(10 * time.Millisecond)
+	}
+	if added, err := store.AddFavourite(ctx, userID, tracks[0]); err != nil || added {
+		t.Fatalf("AddFavourite of saved track = %t, %v, want not added", added, err)
+	}
+
+	tests := []struct {
+		name   string
+		userID snowflake.ID
+		limit  int
+		want   []Favourite
+	}{
+		{
+			name:   "newest first",
+			userID: userID,
+			limit:  10,
+			want: []Favourite{
+				{Title: "Title c", Author: "Author c"},
+				{Title: "Title b", Author: "Author b", URI: "https://example.com/b"},
+				{Title: "Title a", Author: "Author a", URI: "https://example.com/a"},
+			},
+		},
+		{
+			name:   "limited",
+			userID: userID,
+			limit:  1,
+			want:   []Favourite{{Title: "Title c", Author: "Author c"}},
+		},
+		{
+			name:   "other user",
+			userID: otherUserID,
+			limit:  10,
+		},
+	}
+	for _, test := range tests {
+		favourites, err := store.Favourites(ctx, test.userID, test.limit)
+		if err != nil {
+			t.Fatalf("%s: Favourites: %v", test.name, err)
+		}
+		if len(favourites) != len(test.want) {
+			t.Fatalf("%s: got %+v, want %+v", test.name, favourites, test.want)
+		}
+		for i := range favourites {
+			if favourites[i] != test.want[i] {
+				t.Fatalf("%s: got %+v, want %+v", test.name, favourites, test.want)
+			}
+		}
+	}
+}
+
+func testPlayerState(t *testing.T, ctx context.Context, store Store) {
+	current := testTrack("current")
+	first := SavedPlayer{
+		GuildID:        1,
+		VoiceChannelID: 10,
+		Track:          &current,
+		Position:       lavalink.Duration(42_000),
+		Paused:         true,
+		Volume:         80,
+		Loop:           queue.LoopQueue,
+		Autoplay:       true,
+		Queue:          []lavalink.Track{testTrack("next"), testTrack("last")},
+	}
+	// An idle player, like one whose queue ended or a 24/7 player waiting for listeners, has no track
+	second := SavedPlayer{GuildID: 2, VoiceChannelID: 20, Volume: 100, Track: nil}
+
+	if _, ok, err := store.SavedPlayer(ctx, first.GuildID); err != nil || ok {
+		t.Fatalf("SavedPlayer before saving = %t, %v, want none", ok, err)
+	}
+
+	if err := store.SavePlayers(ctx, []SavedPlayer{first, second}); err != nil {
+		t.Fatalf("SavePlayers: %v", err)
+	}
+	assertSavedPlayer(t, ctx, store, first)
+	assertSavedPlayer(t, ctx, store, second)
+
+	// Saving again replaces the given guilds and keeps the others
+	first.Track, first.Position, first.Paused, first.Queue = nil, 0, false, nil
+	if err := store.SavePlayers(ctx, []SavedPlayer{first}); err != nil {
+		t.Fatalf("SavePlayers: %v", err)
+	}
+	assertSavedPlayer(t, ctx, store, first)
+	assertSavedPlayer(t, ctx, store, second)
+
+	if err := store.DeleteSavedPlayer(ctx, second.GuildID); err != nil {
+		t.Fatalf("DeleteSavedPlayer: %v", err)
+	}
+	if _, ok, err := store.SavedPlayer(ctx, second.GuildID); err != nil || ok {
+		t.Fatalf("SavedPlayer after delete = %t, %v, want none", ok, err)
+	}
+	assertSavedPlayer(t, ctx, store, first)
+}
+
+func assertSavedPlayer(t *testing.T, ctx context.Context, store Store, want SavedPlayer) {
+	t.Helper()
+	got, ok, err := store.SavedPlayer(ctx, want.GuildID)
+	if err != nil || !ok {
+		t.Fatalf("SavedPlayer(%d) = %t, %v, want a saved state", want.GuildID, ok, err)
+	}
+
+	if got.GuildID != want.GuildID || got.VoiceChannelID != want.VoiceChannelID || got.Position != want.Position ||
+		got.Paused != want.Paused || got.Volume != want.Volume || got.Loop != want.Loop || got.Autoplay != want.Autoplay {
+		t.Fatalf("SavedPlayer(%d) = %+v, want %+v", want.GuildID, got, want)
+	}
+	if (got.Track == nil) != (want.Track == nil) || got.Track != nil && got.Track.Encoded != want.Track.Encoded {
+		t.Fatalf("SavedPlayer(%d) track = %+v, want %+v", want.GuildID, got.Track, want.Track)
+	}
+	if len(got.Queue) != len(want.Queue) {
+		t.Fatalf("SavedPlayer(%d) queue has %d tracks, want %d", want.GuildID, len(got.Queue), len(want.Queue))
+	}
+	for i := range got.Queue {
+		if got.Queue[i].Encoded != want.Queue[i].Encoded || got.Queue[i].Info.Title != want.Queue[i].Info.Title {
+			t.Fatalf("SavedPlayer(%d) queue[%d] = %+v, want %+v", want.GuildID, i, got.Queue[i], want.Queue[i])
+		}
+	}
+}
+
+func testTrack(name string) lavalink.Track {
+	uri := "https://example.com/" + name
+	return lavalink.Track{
+		Encoded: "encoded-" + name,
+		Info: lavalink.TrackInfo{
+			Identifier: name,
+			Title:      "Title " + name,
+			Author:     "Author " + name,
+			URI:        &uri,
+			SourceName: "http",
+		},
+	}
+}